@@ -1,94 +1,214 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"math"
+	"io"
 	"os"
-	"rayGo/calc"
+	"path/filepath"
 	"rayGo/scene"
+	"rayGo/scenefile"
+	"strings"
+	"time"
 )
 
+//終了コード
+const (
+	exitOK = iota
+	exitRenderError
+	exitUsageError
+	exitSceneError
+)
+
+const usage = `usage: raygo render <scene.yaml> [options]
+
+options:
+  -o, --output <file>   output image, format from extension (.png .ppm .pfm .hdr)
+                        (default: scene file name with .png)
+  --width <n>           override the camera width in pixels
+  --height <n>          override the camera height in pixels
+                        (giving only one keeps the aspect ratio)
+  --samples <n>         n×n jittered samples per pixel (default 1)
+  --workers <n>         number of render goroutines (default: number of CPUs)
+  --max-depth <n>       how many reflections and refractions to follow (default %d)
+  --transfer <t>        encoding for .png and .ppm: linear, srgb or a gamma value
+                        such as 2.2 (default srgb)
+`
+
 func main() {
-	glassfloor := scene.NewPlane()
-	//ポインタじゃないとsetが反映されないっぽい
-	glassfloorMaterial := scene.DefaultMaterial()
-	glassfloorMaterial.SetPattern(scene.NewCheckersPattern(scene.White, scene.Black))
-	glassfloorMaterial.Transparency = 1
-	glassfloorMaterial.Reflective = 1
-	glassfloorMaterial.Shininess = 300
-	glassfloorMaterial.Specular = 1
-	glassfloorMaterial.Diffuse = 0.1
-
-	glassfloor.SetMaterial(glassfloorMaterial)
-
-	wall1 := scene.NewPlane()
-	wall1.SetTransform(calc.MulMatMulti(calc.NewTranslation(0, 0, 10), calc.NewRotateY(-math.Pi/4), calc.NewRotateX(math.Pi/2)))
-	wall1Material := scene.DefaultMaterial()
-	wall1Material.Color = scene.White
-	wall1.SetMaterial(wall1Material)
-
-	wall2 := scene.NewPlane()
-	wall2.SetTransform(calc.MulMatMulti(calc.NewTranslation(0, 0, 10), calc.NewRotateY(math.Pi/4), calc.NewRotateX(math.Pi/2)))
-	wall2Material := scene.DefaultMaterial()
-	wall2Material.Color = scene.White
-	wall2.SetMaterial(wall2Material)
-
-	middle := scene.NewSphere(1)
-	middle.SetTransform(calc.NewTranslation(-0.5, 1, 0.5))
-	middleMaterial := scene.DefaultMaterial()
-	middleMaterial.Color = scene.NewColor(1, 0, 0)
-	middleMaterial.Diffuse = 0.1
-	middleMaterial.Ambient = 0.1
-	middleMaterial.Transparency = 1.0
-	middleMaterial.RefractiveIndex = 1.5
-
-	middle.SetMaterial(middleMaterial)
-
-	right := scene.NewSphere(1)
-	right.SetTransform(calc.NewTranslation(1.5, 0.5, -0.5).MulByMat4x4(calc.NewScale(0.5, 0.5, 0.5)))
-	rightMaterial := scene.DefaultMaterial()
-	rightMaterial.Color = scene.NewColor(0.5, 1, 0.1)
-	rightMaterial.Diffuse = 0.1
-	rightMaterial.Ambient = 0.1
-	rightMaterial.Transparency = 1.0
-	rightMaterial.RefractiveIndex = 1.5
-
-	right.SetMaterial(rightMaterial)
-
-	left := scene.NewSphere(1)
-	left.SetTransform(calc.NewTranslation(-1.5, 0.33, -0.75).MulByMat4x4(calc.NewScale(0.33, 0.33, 0.33)))
-	leftMaterial := scene.DefaultMaterial()
-	leftMaterial.Color = scene.Blue
-	leftMaterial.Diffuse = 0.1
-	leftMaterial.Ambient = 0.1
-	leftMaterial.Transparency = 1.0
-	leftMaterial.RefractiveIndex = 1.5
-
-	left.SetMaterial(leftMaterial)
-
-	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), glassfloor, middle, left, right, wall1, wall2)
-
-	camera := scene.NewCamera(960, 1200, math.Pi/3)
-
-	camera.Transform = scene.ViewTransform(
-		calc.NewPoint(0, 1.5, -5),
-		calc.NewPoint(-1.25, -0.7, 0),
-		calc.NewVector(0, 1, 0),
-	)
-
-	canvas, err := world.Render(camera)
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, usage, scene.DefaultMaxDepth)
+		return exitUsageError
+	}
+
+	switch args[0] {
+	case "render":
+		return runRender(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprintf(stdout, usage, scene.DefaultMaxDepth)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "raygo: unknown command %q\n", args[0])
+		fmt.Fprintf(stderr, usage, scene.DefaultMaxDepth)
+		return exitUsageError
+	}
+}
+
+type renderFlags struct {
+	scenePath string
+	output    string
+	width     int
+	height    int
+	samples   int
+	workers   int
+	maxDepth  int
+	transfer  scene.Transfer
+}
+
+//flagは最初のflagでない引数で止まるので、scene fileの後ろに書いたflagも読めるように繰り返す
+func parseRenderFlags(args []string) (*renderFlags, error) {
+	f := &renderFlags{}
+
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&f.output, "o", "", "")
+	fs.StringVar(&f.output, "output", "", "")
+	fs.IntVar(&f.width, "width", 0, "")
+	fs.IntVar(&f.height, "height", 0, "")
+	fs.IntVar(&f.samples, "samples", 1, "")
+	fs.IntVar(&f.workers, "workers", 0, "")
+	fs.IntVar(&f.maxDepth, "max-depth", scene.DefaultMaxDepth, "")
+	//画像viewerはsRGBとして表示するので、8bitの形式は省略時にsRGBでencodeする
+	transferName := fs.String("transfer", "srgb", "")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch {
+	case len(positional) == 0:
+		return nil, errors.New("missing scene file")
+	case len(positional) > 1:
+		return nil, fmt.Errorf("unexpected argument %q", positional[1])
+	case f.width < 0:
+		return nil, fmt.Errorf("--width must be positive, got %d", f.width)
+	case f.height < 0:
+		return nil, fmt.Errorf("--height must be positive, got %d", f.height)
+	case f.samples < 1:
+		return nil, fmt.Errorf("--samples must be at least 1, got %d", f.samples)
+	case f.workers < 0:
+		return nil, fmt.Errorf("--workers must be positive, got %d", f.workers)
+	case f.maxDepth < 0:
+		return nil, fmt.Errorf("--max-depth must not be negative, got %d", f.maxDepth)
+	}
+
+	transfer, err := scenefile.ParseTransfer(*transferName)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return nil, fmt.Errorf("--%v", err)
 	}
+	f.transfer = transfer
+
+	f.scenePath = positional[0]
+	if f.output == "" {
+		f.output = strings.TrimSuffix(filepath.Base(f.scenePath), filepath.Ext(f.scenePath)) + ".png"
+	}
+
+	//renderしてから保存できないと分かっても遅いので先に確かめる
+	switch strings.ToLower(filepath.Ext(f.output)) {
+	case ".ppm", ".png", ".pfm", ".hdr":
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", f.output)
+	}
+
+	return f, nil
+}
 
-	fp, err := os.Create("test.ppm")
+func runRender(args []string, stdout, stderr io.Writer) int {
+	f, err := parseRenderFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(stdout, usage, scene.DefaultMaxDepth)
+		return exitOK
+	}
 	if err != nil {
-		fmt.Println(err)
-		return
+		fmt.Fprintf(stderr, "raygo render: %v\n", err)
+		fmt.Fprintf(stderr, usage, scene.DefaultMaxDepth)
+		return exitUsageError
+	}
+
+	start := time.Now()
+
+	var sceneOptions []scenefile.Option
+	if f.width > 0 {
+		sceneOptions = append(sceneOptions, scenefile.CameraWidth(f.width))
+	}
+	if f.height > 0 {
+		sceneOptions = append(sceneOptions, scenefile.CameraHeight(f.height))
 	}
 
-	defer fp.Close()
+	s, err := scenefile.LoadFile(f.scenePath, sceneOptions...)
+	if err != nil {
+		fmt.Fprintf(stderr, "raygo: %v\n", err)
+		return exitSceneError
+	}
+	loaded := time.Now()
+
+	//fileに書いた設定の上にcommand lineの指定を重ねる
+	renderOptions := []scene.RenderOption{
+		scene.RenderWithSettings(s.Settings),
+		scene.RenderMaxDepth(f.maxDepth),
+	}
+	if f.samples > 1 {
+		renderOptions = append(renderOptions, scene.RenderSampler(scene.NewJitteredSampler(f.samples)))
+	}
+	if f.workers > 0 {
+		renderOptions = append(renderOptions, scene.RenderWorkers(f.workers))
+	}
+
+	canvas, err := s.World.Render(s.Camera, renderOptions...)
+	if err != nil {
+		fmt.Fprintf(stderr, "raygo: render failed: %v\n", err)
+		return exitRenderError
+	}
+	rendered := time.Now()
+
+	//PFMとHDRはlinearのまま書き出すのでtransferは使われない
+	if err := canvas.Save(f.output, scene.ImageTransfer(f.transfer)); err != nil {
+		fmt.Fprintf(stderr, "raygo: %v\n", err)
+		return exitRenderError
+	}
+	saved := time.Now()
+
+	pixels := canvas.Width * canvas.Height
+	renderTime := rendered.Sub(loaded)
+	fmt.Fprintf(stdout, "wrote %s (%dx%d, %d samples/pixel)\n", f.output, canvas.Width, canvas.Height, f.samples*f.samples)
+	fmt.Fprintf(stdout, "  load   %v\n", loaded.Sub(start).Round(time.Millisecond))
+	fmt.Fprintf(stdout, "  render %v%s\n", renderTime.Round(time.Millisecond), renderRate(pixels, renderTime))
+	fmt.Fprintf(stdout, "  save   %v\n", saved.Sub(rendered).Round(time.Millisecond))
+	fmt.Fprintf(stdout, "  total  %v\n", saved.Sub(start).Round(time.Millisecond))
+
+	return exitOK
+}
+
+//一瞬で終わったときや時計の粒度が粗いときは時間が0になるので速さは出さない
+func renderRate(pixels int, d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
 
-	fp.WriteString(canvas.ToPPM())
+	return fmt.Sprintf(" (%.0f pixels/s)", float64(pixels)/d.Seconds())
 }
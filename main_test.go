@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"rayGo/scene"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testScene = `
+- add: camera
+  width: 8
+  height: 6
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+- add: light
+  at: [-10, 10, -10]
+- add: sphere
+`
+
+func writeTestScene(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "scene.yaml")
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+func Test_Run_Render(t *testing.T) {
+	dir, err := ioutil.TempDir("", "raygo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	scenePath := writeTestScene(t, dir, testScene)
+	output := filepath.Join(dir, "out.ppm")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", scenePath, "-o", output, "--width", "4", "--samples", "2", "--workers", "2", "--max-depth", "1"}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	require.Empty(t, stderr.String())
+	require.Contains(t, stdout.String(), "wrote "+output+" (4x3, 4 samples/pixel)")
+	require.Contains(t, stdout.String(), "render ")
+
+	canvas, err := scene.LoadCanvas(output)
+	require.Nil(t, err)
+	require.Equal(t, 4, canvas.Width)
+	require.Equal(t, 3, canvas.Height)
+}
+
+func Test_Run_Default_Output_Name(t *testing.T) {
+	f, err := parseRenderFlags([]string{"--width", "10", "scenes/room.yaml"})
+	require.Nil(t, err)
+	require.Equal(t, "scenes/room.yaml", f.scenePath)
+	require.Equal(t, "room.png", f.output)
+	require.Equal(t, 10, f.width)
+	require.Equal(t, 1, f.samples)
+	require.Equal(t, scene.DefaultMaxDepth, f.maxDepth)
+	require.Equal(t, scene.SRGBTransfer{}, f.transfer)
+}
+
+func Test_Run_Transfer_Flag(t *testing.T) {
+	for _, target := range []struct {
+		name string
+		ans  scene.Transfer
+	}{
+		{"linear", scene.LinearTransfer{}},
+		{"srgb", scene.SRGBTransfer{}},
+		{"2.2", scene.NewGammaTransfer(2.2)},
+	} {
+		f, err := parseRenderFlags([]string{"scene.yaml", "--transfer", target.name})
+		require.Nil(t, err, target.name)
+		require.Equal(t, target.ans, f.transfer, target.name)
+	}
+}
+
+func Test_Run_Encodes_Output_With_Transfer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "raygo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	gray := scene.NewColor(0.2, 0.2, 0.2)
+	scenePath := writeTestScene(t, dir, testScene+"- add: background\n  type: solid\n  color: [0.2, 0.2, 0.2]\n")
+
+	for _, target := range []struct {
+		args     []string
+		transfer scene.Transfer
+	}{
+		{nil, scene.SRGBTransfer{}},
+		{[]string{"--transfer", "linear"}, scene.LinearTransfer{}},
+		{[]string{"--transfer", "2.2"}, scene.NewGammaTransfer(2.2)},
+	} {
+		for _, ext := range []string{".ppm", ".png"} {
+			output := filepath.Join(dir, "out"+ext)
+
+			var stdout, stderr bytes.Buffer
+			code := run(append([]string{"render", scenePath, "-o", output}, target.args...), &stdout, &stderr)
+			require.Equal(t, exitOK, code, stderr.String())
+
+			//同じtransferでdecodeすると元の明るさに戻る
+			canvas, err := scene.LoadCanvas(output, scene.ImageTransfer(target.transfer))
+			require.Nil(t, err)
+			require.InDelta(t, gray.Red, canvas.Pixels[0][0].Red, 0.01, target.args)
+		}
+	}
+}
+
+func Test_Run_Usage_Errors(t *testing.T) {
+	for _, target := range []struct {
+		args []string
+		ans  string
+	}{
+		{nil, "usage: raygo"},
+		{[]string{"draw"}, `unknown command "draw"`},
+		{[]string{"render"}, "missing scene file"},
+		{[]string{"render", "a.yaml", "b.yaml"}, `unexpected argument "b.yaml"`},
+		{[]string{"render", "a.yaml", "--samples", "0"}, "--samples must be at least 1"},
+		{[]string{"render", "a.yaml", "--width", "-1"}, "--width must be positive"},
+		{[]string{"render", "a.yaml", "--max-depth", "-1"}, "--max-depth must not be negative"},
+		{[]string{"render", "a.yaml", "--bogus"}, "flag provided but not defined: -bogus"},
+		{[]string{"render", "a.yaml", "-o", "a.jpg"}, `unsupported output format "a.jpg"`},
+		{[]string{"render", "a.yaml", "--transfer", "rec709"}, `--transfer must be linear, srgb or a positive gamma, got "rec709"`},
+		{[]string{"render", "a.yaml", "--transfer", "0"}, `--transfer must be linear, srgb or a positive gamma, got "0"`},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(target.args, &stdout, &stderr)
+		require.Equal(t, exitUsageError, code, target.args)
+		require.Contains(t, stderr.String(), target.ans, target.args)
+		require.Empty(t, stdout.String(), target.args)
+	}
+}
+
+func Test_Run_Scene_Error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "raygo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	scenePath := writeTestScene(t, dir, testScene+"- add: teapot\n")
+	output := filepath.Join(dir, "out.png")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", scenePath, "-o", output}, &stdout, &stderr)
+	require.Equal(t, exitSceneError, code)
+	require.Equal(t, "raygo: "+scenePath+":10:8: unknown shape \"teapot\"\n", stderr.String())
+
+	_, err = os.Stat(output)
+	require.True(t, os.IsNotExist(err))
+}
+
+func Test_Run_Save_Error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "raygo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	scenePath := writeTestScene(t, dir, testScene)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", scenePath, "-o", filepath.Join(dir, "missing", "out.png")}, &stdout, &stderr)
+	require.Equal(t, exitRenderError, code)
+	require.Contains(t, stderr.String(), "raygo: ")
+}
+
+func Test_Run_Uses_Scene_Background(t *testing.T) {
+	dir, err := ioutil.TempDir("", "raygo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	scenePath := writeTestScene(t, dir, testScene+"- add: background\n  type: solid\n  color: [1, 0, 0]\n")
+	output := filepath.Join(dir, "out.ppm")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", scenePath, "-o", output}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	canvas, err := scene.LoadCanvas(output)
+	require.Nil(t, err)
+	require.Equal(t, scene.Red, canvas.Pixels[0][0])
+}
+
+func Test_Render_Rate(t *testing.T) {
+	require.Equal(t, " (200 pixels/s)", renderRate(100, 500*time.Millisecond))
+	//時間が0でも+Infにしない
+	require.Equal(t, "", renderRate(100, 0))
+}
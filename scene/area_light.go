@@ -0,0 +1,239 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//Cornerから(UVec*USteps)と(VVec*VSteps)で張られる長方形の光源
+//USteps*VSteps個のcellそれぞれから一点ずつ取って影を調べる
+type AreaLight struct {
+	Corner    calc.Tuple4
+	UVec      calc.Tuple4
+	USteps    int
+	VVec      calc.Tuple4
+	VSteps    int
+	Position  calc.Tuple4
+	Intensity Color
+	//falseのときはcellの中心を使う、縞模様が出る代わりにtestで結果が決まる
+	Jitter bool
+	Seed   uint64
+}
+
+var _ LightSource = AreaLight{}
+
+//fullUVec,fullVVecは長方形の辺そのもので、Steps個に分割される
+func NewAreaLight(corner, fullUVec calc.Tuple4, uSteps int, fullVVec calc.Tuple4, vSteps int, intensity Color) AreaLight {
+	if uSteps < 1 {
+		uSteps = 1
+	}
+
+	if vSteps < 1 {
+		vSteps = 1
+	}
+
+	position := calc.AddTuple(
+		corner,
+		calc.AddTuple(calc.MulTupleByScalar(0.5, fullUVec), calc.MulTupleByScalar(0.5, fullVVec)),
+	)
+
+	return AreaLight{
+		Corner:    corner,
+		UVec:      calc.MulTupleByScalar(1/float64(uSteps), fullUVec),
+		USteps:    uSteps,
+		VVec:      calc.MulTupleByScalar(1/float64(vSteps), fullVVec),
+		VSteps:    vSteps,
+		Position:  position,
+		Intensity: intensity,
+		Jitter:    true,
+	}
+}
+
+//cellごとに一点ずつなので、sampleの数はStepsで決まる
+func (l AreaLight) SampleCount() int {
+	return l.USteps * l.VSteps
+}
+
+//rngがnilのときはcellの中心
+func (l AreaLight) PointOnLight(u, v int, rng *Random) calc.Tuple4 {
+	ju, jv := 0.5, 0.5
+	if rng != nil {
+		ju, jv = rng.Float64(), rng.Float64()
+	}
+
+	return calc.AddTuple(
+		l.Corner,
+		calc.AddTuple(
+			calc.MulTupleByScalar(float64(u)+ju, l.UVec),
+			calc.MulTupleByScalar(float64(v)+jv, l.VVec),
+		),
+	)
+}
+
+func (l AreaLight) samplePoints(point calc.Tuple4) []calc.Tuple4 {
+	var rng *Random
+	if l.Jitter {
+		rng = NewRandom(hashPoint(l.Seed, point))
+	}
+
+	points := make([]calc.Tuple4, 0, l.SampleCount())
+	for v := 0; v < l.VSteps; v++ {
+		for u := 0; u < l.USteps; u++ {
+			points = append(points, l.PointOnLight(u, v, rng))
+		}
+	}
+
+	return points
+}
+
+func (l AreaLight) IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	return sampledIntensityAt(l.samplePoints(point), point, w, settings)
+}
+
+func (l AreaLight) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
+	return lighting(l, m, position, eye_vec, normal_vec, intensity, shape)
+}
+
+func (l AreaLight) DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	return sampledDirectLighting(l.samplePoints(position), l.Intensity, m, materialColor, position, eye_vec, normal_vec, intensity)
+}
+
+func (l AreaLight) GetIntensity() Color {
+	return l.Intensity
+}
+
+//AreaLightとSphereLight、光源の上にsampleを散らして影と明るさを求める
+//sampleの乱数はpointから決まるので、WorldはOverPointで一度だけsampleを取って影とdiffuse,specularの両方に使う
+type sampledLight interface {
+	LightSource
+	samplePoints(point calc.Tuple4) []calc.Tuple4
+}
+
+var _ sampledLight = AreaLight{}
+var _ sampledLight = SphereLight{}
+
+//光源の面上に散らばったsampleのうち影にならないものの割合
+func sampledIntensityAt(samples []calc.Tuple4, point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	if len(samples) == 0 {
+		return 0, nil
+	}
+
+	total := 0.0
+	for _, sample := range samples {
+		in_shadow, err := w.IsShadowed(sample, point, settings)
+		if err != nil {
+			return 0, err
+		}
+
+		if !in_shadow {
+			total += 1
+		}
+	}
+
+	return total / float64(len(samples)), nil
+}
+
+//ambientは含めない、diffuseとspecularは各sampleを点光源とみなして平均を取る
+func sampledDirectLighting(samples []calc.Tuple4, lightIntensity Color, m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	if intensity == 0 || len(samples) == 0 {
+		return Black
+	}
+
+	effective_color := materialColor.Mul(lightIntensity)
+
+	sum := Black
+	for _, sample := range samples {
+		diffuse, specular := diffuseAndSpecular(m, effective_color, lightIntensity, calc.SubTuple(sample, position).Normalize(), eye_vec, normal_vec)
+		sum = sum.Add(diffuse).Add(specular)
+	}
+
+	return sum.MulByScalar(intensity / float64(len(samples)))
+}
+
+//Centerを中心とする球の光源
+//照らされるpointから見た球の輪郭(円盤)の上にSamples個の点を取る
+type SphereLight struct {
+	Center    calc.Tuple4
+	Radius    float64
+	Samples   int
+	Intensity Color
+	Jitter    bool
+	Seed      uint64
+}
+
+var _ LightSource = SphereLight{}
+
+func NewSphereLight(center calc.Tuple4, radius float64, samples int, intensity Color) SphereLight {
+	if samples < 1 {
+		samples = 1
+	}
+
+	return SphereLight{
+		Center:    center,
+		Radius:    radius,
+		Samples:   samples,
+		Intensity: intensity,
+		Jitter:    true,
+	}
+}
+
+//黄金角で回しながら半径を広げていくと円盤の上にほぼ均等に点が並ぶ
+var goldenAngle = math.Pi * (3 - math.Sqrt(5))
+
+func (l SphereLight) samplePoints(point calc.Tuple4) []calc.Tuple4 {
+	toPoint := calc.SubTuple(point, l.Center)
+
+	//光源の内側にいるときは中心だけを使う
+	if l.Radius <= 0 || toPoint.Magnitude() <= l.Radius {
+		return []calc.Tuple4{l.Center}
+	}
+
+	//円盤はpointへの向きに垂直、その面を張る二つのvectorを作る
+	w := toPoint.Normalize()
+	up := calc.NewVector(0, 1, 0)
+	if math.Abs(w[1]) > 0.9 {
+		up = calc.NewVector(1, 0, 0)
+	}
+	u := calc.CrossTuple(up, w).Normalize()
+	v := calc.CrossTuple(w, u)
+
+	var rng *Random
+	if l.Jitter {
+		rng = NewRandom(hashPoint(l.Seed, point))
+	}
+
+	points := make([]calc.Tuple4, 0, l.Samples)
+	for i := 0; i < l.Samples; i++ {
+		jr, jt := 0.5, 0.0
+		if rng != nil {
+			jr, jt = rng.Float64(), rng.Float64()
+		}
+
+		r := l.Radius * math.Sqrt((float64(i)+jr)/float64(l.Samples))
+		theta := goldenAngle * (float64(i) + jt)
+
+		offset := calc.AddTuple(
+			calc.MulTupleByScalar(r*math.Cos(theta), u),
+			calc.MulTupleByScalar(r*math.Sin(theta), v),
+		)
+		points = append(points, calc.AddTuple(l.Center, offset))
+	}
+
+	return points
+}
+
+func (l SphereLight) IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	return sampledIntensityAt(l.samplePoints(point), point, w, settings)
+}
+
+func (l SphereLight) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
+	return lighting(l, m, position, eye_vec, normal_vec, intensity, shape)
+}
+
+func (l SphereLight) DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	return sampledDirectLighting(l.samplePoints(position), l.Intensity, m, materialColor, position, eye_vec, normal_vec, intensity)
+}
+
+func (l SphereLight) GetIntensity() Color {
+	return l.Intensity
+}
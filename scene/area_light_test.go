@@ -0,0 +1,275 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Point_Light_IntensityAt(t *testing.T) {
+	w := DefaultWorld()
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   float64
+	}{
+		{calc.NewPoint(0, 1.0001, 0), 1.0},
+		{calc.NewPoint(-1.0001, 0, 0), 1.0},
+		{calc.NewPoint(0, 0, -1.0001), 1.0},
+		{calc.NewPoint(0, 0, 1.0001), 0.0},
+		{calc.NewPoint(1.0001, 0, 0), 0.0},
+		{calc.NewPoint(0, -1.0001, 0), 0.0},
+		{calc.NewPoint(0, 0, 0), 0.0},
+	} {
+		intensity, err := w.Lights[0].IntensityAt(target.point, w, DefaultRenderSettings())
+		require.Nil(t, err)
+		require.Equal(t, target.ans, intensity)
+	}
+}
+
+func Test_Lighting_Uses_Light_Intensity(t *testing.T) {
+	w := DefaultWorld()
+	w.Lights = []LightSource{NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))}
+
+	shape := w.Objects[0]
+	m := shape.GetMaterial()
+	m.Ambient = 0.1
+	m.Diffuse = 0.9
+	m.Specular = 0
+	m.Color = NewColor(1, 1, 1)
+
+	pt := calc.NewPoint(0, 0, -1)
+	eye_vec := calc.NewVector(0, 0, -1)
+	normal_vec := calc.NewVector(0, 0, -1)
+
+	for _, target := range []struct {
+		intensity float64
+		ans       Color
+	}{
+		{1.0, NewColor(1, 1, 1)},
+		{0.5, NewColor(0.55, 0.55, 0.55)},
+		{0.0, NewColor(0.1, 0.1, 0.1)},
+	} {
+		res, err := w.Lights[0].Lighting(m, pt, eye_vec, normal_vec, target.intensity, shape)
+		require.Nil(t, err)
+		require.True(t, colorCompare(target.ans, res), res)
+	}
+}
+
+func Test_Create_AreaLight(t *testing.T) {
+	light := NewAreaLight(
+		calc.NewPoint(0, 0, 0),
+		calc.NewVector(2, 0, 0), 4,
+		calc.NewVector(0, 0, 1), 2,
+		NewColor(1, 1, 1),
+	)
+
+	require.Equal(t, calc.NewPoint(0, 0, 0), light.Corner)
+	require.Equal(t, calc.NewVector(0.5, 0, 0), light.UVec)
+	require.Equal(t, 4, light.USteps)
+	require.Equal(t, calc.NewVector(0, 0, 0.5), light.VVec)
+	require.Equal(t, 2, light.VSteps)
+	require.Equal(t, 8, light.SampleCount())
+	require.Equal(t, calc.NewPoint(1, 0, 0.5), light.Position)
+
+	//Stepsを変えるとsampleの数も変わる
+	light.USteps = 2
+	require.Equal(t, 4, light.SampleCount())
+	require.Equal(t, 4, len(light.samplePoints(calc.NewPoint(0, 0, 0))))
+}
+
+func Test_Point_On_AreaLight(t *testing.T) {
+	light := NewAreaLight(
+		calc.NewPoint(0, 0, 0),
+		calc.NewVector(2, 0, 0), 4,
+		calc.NewVector(0, 0, 1), 2,
+		NewColor(1, 1, 1),
+	)
+
+	for _, target := range []struct {
+		u   int
+		v   int
+		ans calc.Tuple4
+	}{
+		{0, 0, calc.NewPoint(0.25, 0, 0.25)},
+		{1, 0, calc.NewPoint(0.75, 0, 0.25)},
+		{0, 1, calc.NewPoint(0.25, 0, 0.75)},
+		{2, 0, calc.NewPoint(1.25, 0, 0.25)},
+		{3, 1, calc.NewPoint(1.75, 0, 0.75)},
+	} {
+		require.True(t, calc.TupleCompare(target.ans, light.PointOnLight(target.u, target.v, nil)))
+	}
+}
+
+func Test_Jittered_Point_Stays_In_Cell(t *testing.T) {
+	light := NewAreaLight(
+		calc.NewPoint(0, 0, 0),
+		calc.NewVector(2, 0, 0), 4,
+		calc.NewVector(0, 0, 1), 2,
+		NewColor(1, 1, 1),
+	)
+
+	rng := NewRandom(1)
+	for i := 0; i < 100; i++ {
+		p := light.PointOnLight(3, 1, rng)
+		require.True(t, 1.5 <= p[0] && p[0] < 2)
+		require.True(t, 0.5 <= p[2] && p[2] < 1)
+	}
+}
+
+func Test_AreaLight_IntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	light := NewAreaLight(
+		calc.NewPoint(-0.5, -0.5, -5),
+		calc.NewVector(1, 0, 0), 2,
+		calc.NewVector(0, 1, 0), 2,
+		NewColor(1, 1, 1),
+	)
+	light.Jitter = false
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   float64
+	}{
+		{calc.NewPoint(0, 0, 2), 0.0},
+		{calc.NewPoint(1, -1, 2), 0.25},
+		{calc.NewPoint(1.5, 0, 2), 0.5},
+		{calc.NewPoint(1.25, 1.25, 3), 0.75},
+		{calc.NewPoint(0, 0, -2), 1.0},
+	} {
+		intensity, err := light.IntensityAt(target.point, w, DefaultRenderSettings())
+		require.Nil(t, err)
+		require.Equal(t, target.ans, intensity)
+	}
+}
+
+func Test_AreaLight_Jitter_Is_Deterministic(t *testing.T) {
+	w := DefaultWorld()
+	light := NewAreaLight(
+		calc.NewPoint(-0.5, -0.5, -5),
+		calc.NewVector(1, 0, 0), 4,
+		calc.NewVector(0, 1, 0), 4,
+		NewColor(1, 1, 1),
+	)
+
+	p := calc.NewPoint(1.5, 0, 2)
+	first, err := light.IntensityAt(p, w, DefaultRenderSettings())
+	require.Nil(t, err)
+
+	second, err := light.IntensityAt(p, w, DefaultRenderSettings())
+	require.Nil(t, err)
+
+	require.Equal(t, first, second)
+	require.True(t, 0 < first && first < 1)
+}
+
+func Test_AreaLight_Lighting_Samples_Light(t *testing.T) {
+	light := NewAreaLight(
+		calc.NewPoint(-0.5, -0.5, -5),
+		calc.NewVector(1, 0, 0), 2,
+		calc.NewVector(0, 1, 0), 2,
+		NewColor(1, 1, 1),
+	)
+	light.Jitter = false
+
+	shape := NewSphere(1)
+	m := shape.GetMaterial()
+	m.Ambient = 0.1
+	m.Diffuse = 0.9
+	m.Specular = 0
+	m.Color = NewColor(1, 1, 1)
+
+	eye := calc.NewPoint(0, 0, -5)
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   Color
+	}{
+		{calc.NewPoint(0, 0, -1), NewColor(0.9965, 0.9965, 0.9965)},
+		{calc.NewPoint(0, 0.7071, -0.7071), NewColor(0.6232, 0.6232, 0.6232)},
+	} {
+		eye_vec := calc.SubTuple(eye, target.point).Normalize()
+		normal_vec := calc.NewVector(target.point[0], target.point[1], target.point[2])
+
+		res, err := light.Lighting(m, target.point, eye_vec, normal_vec, 1.0, shape)
+		require.Nil(t, err)
+		require.True(t, math.Abs(target.ans.Red-res.Red) < 0.0001, res)
+		require.True(t, math.Abs(target.ans.Blue-res.Blue) < 0.0001, res)
+	}
+}
+
+func Test_Shade_Hit_Uses_Same_Samples_For_Shadow_And_Lighting(t *testing.T) {
+	w := DefaultWorld()
+	w.Lights = []LightSource{
+		NewAreaLight(
+			calc.NewPoint(-10.5, 9.5, -10),
+			calc.NewVector(1, 0, 0), 4,
+			calc.NewVector(0, 1, 0), 4,
+			NewColor(1, 1, 1),
+		),
+		NewSphereLight(calc.NewPoint(-10, 10, -10), 0.5, 16, NewColor(1, 1, 1)),
+	}
+
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+	xs, err := w.Intersect(r)
+	require.Nil(t, err)
+	comps, err := PrepareComputations(*GenerateHit(xs), r, xs)
+	require.Nil(t, err)
+
+	settings := DefaultRenderSettings()
+	m := comps.Object.GetMaterial()
+	materialColor, err := m.GetMaterialColor(comps.RayPoint, comps.shadingObject())
+	require.Nil(t, err)
+
+	for _, light := range w.Lights {
+		sampled := light.(sampledLight)
+
+		//RayPointから取り直したsampleはOverPointのものと乱数が違う
+		overSamples := sampled.samplePoints(comps.OverPoint)
+		require.NotEqual(t, overSamples, sampled.samplePoints(comps.RayPoint))
+
+		intensity, err := sampledIntensityAt(overSamples, comps.OverPoint, w, settings)
+		require.Nil(t, err)
+		expected := sampledDirectLighting(overSamples, light.GetIntensity(), m, materialColor, comps.RayPoint, comps.EyeVec, comps.NormalVec, intensity)
+
+		direct, err := w.directLighting(light, m, materialColor, comps, settings)
+		require.Nil(t, err)
+		require.Equal(t, expected, direct)
+	}
+}
+
+func Test_SphereLight_IntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	light := NewSphereLight(calc.NewPoint(0, 0, -5), 0.5, 16, NewColor(1, 1, 1))
+
+	//球の真後ろは完全に影、真正面は全く遮られない
+	behind, err := light.IntensityAt(calc.NewPoint(0, 0, 2), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.Equal(t, 0.0, behind)
+
+	front, err := light.IntensityAt(calc.NewPoint(0, 0, -2), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.Equal(t, 1.0, front)
+
+	//影の縁では一部のsampleだけが遮られる
+	edge, err := light.IntensityAt(calc.NewPoint(1.3, 0, 2), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.True(t, 0 < edge && edge < 1, edge)
+}
+
+func Test_SphereLight_Samples_Lie_On_Disk(t *testing.T) {
+	light := NewSphereLight(calc.NewPoint(1, 2, 3), 0.5, 32, NewColor(1, 1, 1))
+	point := calc.NewPoint(1, 2, -3)
+
+	samples := light.samplePoints(point)
+	require.Equal(t, 32, len(samples))
+
+	for _, s := range samples {
+		offset := calc.SubTuple(s, light.Center)
+		require.True(t, offset.Magnitude() <= light.Radius)
+		//円盤はpointへの向きに垂直
+		require.True(t, math.Abs(offset[2]) < 0.00001)
+	}
+}
@@ -0,0 +1,64 @@
+package scene
+
+//光源からの距離dでdiffuseとspecularを1/(Constant + Linear*d + Quadratic*d^2)倍する
+//zero valueは減衰なしとして扱うので、今までのsceneはそのまま同じ見た目になる
+type Attenuation struct {
+	Constant  float64
+	Linear    float64
+	Quadratic float64
+}
+
+var NoAttenuation = Attenuation{Constant: 1}
+
+//物理的に正しい距離の二乗での減衰
+var InverseSquareAttenuation = Attenuation{Quadratic: 1}
+
+func NewAttenuation(constant, linear, quadratic float64) Attenuation {
+	return Attenuation{
+		Constant:  constant,
+		Linear:    linear,
+		Quadratic: quadratic,
+	}
+}
+
+func (a Attenuation) IsNone() bool {
+	return a == Attenuation{} || a == NoAttenuation
+}
+
+func (a Attenuation) Factor(distance float64) float64 {
+	if a.IsNone() {
+		return 1
+	}
+
+	denominator := a.Constant + a.Linear*distance + a.Quadratic*distance*distance
+	//光源にぴったり重なる点で無限大にならないようにする
+	if denominator <= 0 {
+		return 1
+	}
+
+	return 1 / denominator
+}
+
+type LightOptions struct {
+	Attenuation Attenuation
+}
+
+type LightOption func(*LightOptions)
+
+func LightAttenuation(a Attenuation) LightOption {
+	return func(o *LightOptions) {
+		o.Attenuation = a
+	}
+}
+
+func newLightOptions(options ...LightOption) *LightOptions {
+	defaultOptions := &LightOptions{
+		NoAttenuation,
+	}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
+
+	return defaultOptions
+}
@@ -0,0 +1,80 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Attenuation_Factor(t *testing.T) {
+	for _, target := range []struct {
+		title       string
+		attenuation Attenuation
+		distance    float64
+		ans         float64
+	}{
+		{"zero value is none", Attenuation{}, 10, 1},
+		{"none", NoAttenuation, 10, 1},
+		{"inverse square", InverseSquareAttenuation, 4, 1.0 / 16},
+		{"constant linear quadratic", NewAttenuation(1, 0.5, 0.25), 2, 1.0 / 3},
+		{"never amplifies at the light", InverseSquareAttenuation, 0, 1},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			require.True(t, math.Abs(target.ans-target.attenuation.Factor(target.distance)) < 0.00001)
+		})
+	}
+}
+
+func Test_Light_Defaults_To_No_Attenuation(t *testing.T) {
+	light := NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	require.True(t, light.Attenuation.IsNone())
+
+	spot := NewSpotLight(calc.NewPoint(0, 0, -10), calc.NewVector(0, 0, 1), math.Pi/8, math.Pi/6, NewColor(1, 1, 1))
+	require.True(t, spot.Attenuation.IsNone())
+}
+
+func Test_Lighting_With_Attenuation(t *testing.T) {
+	m := DefaultMaterial()
+	s := NewSphere(1)
+	pos := calc.NewPoint(0, 0, 0)
+	eye_vec := calc.NewVector(0, 0, -1)
+	normal_vec := calc.NewVector(0, 0, -1)
+
+	//ambient 0.1は減衰せず、diffuse 0.9とspecular 0.9が1/4になる
+	ans := NewColor(0.1+1.8/4, 0.1+1.8/4, 0.1+1.8/4)
+
+	for _, target := range []struct {
+		title string
+		light LightSource
+	}{
+		{"point light", NewLight(calc.NewPoint(0, 0, -2), NewColor(1, 1, 1), LightAttenuation(InverseSquareAttenuation))},
+		{"spot light", NewSpotLight(calc.NewPoint(0, 0, -2), calc.NewVector(0, 0, 1), math.Pi/8, math.Pi/6, NewColor(1, 1, 1), LightAttenuation(InverseSquareAttenuation))},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			res, err := target.light.Lighting(m, pos, eye_vec, normal_vec, 1.0, s)
+			require.Nil(t, err)
+			require.True(t, colorCompare(ans, res), res)
+		})
+	}
+}
+
+func Test_Moving_Light_Away_Dims_Scene(t *testing.T) {
+	m := DefaultMaterial()
+	s := NewSphere(1)
+	pos := calc.NewPoint(0, 0, 0)
+	eye_vec := calc.NewVector(0, 0, -1)
+	normal_vec := calc.NewVector(0, 0, -1)
+
+	near := NewLight(calc.NewPoint(0, 0, -2), NewColor(1, 1, 1), LightAttenuation(NewAttenuation(1, 0.1, 0.01)))
+	far := NewLight(calc.NewPoint(0, 0, -20), NewColor(1, 1, 1), LightAttenuation(NewAttenuation(1, 0.1, 0.01)))
+
+	c1, err := near.Lighting(m, pos, eye_vec, normal_vec, 1.0, s)
+	require.Nil(t, err)
+
+	c2, err := far.Lighting(m, pos, eye_vec, normal_vec, 1.0, s)
+	require.Nil(t, err)
+
+	require.True(t, c2.Red < c1.Red)
+}
@@ -0,0 +1,108 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//何にも当たらなかったrayの色、向きだけで決まる
+type Background interface {
+	ColorFor(direction calc.Tuple4) Color
+}
+
+//どの向きも同じ色
+type SolidBackground struct {
+	Color Color
+}
+
+var _ Background = SolidBackground{}
+
+func NewSolidBackground(c Color) SolidBackground {
+	return SolidBackground{
+		Color: c,
+	}
+}
+
+func (b SolidBackground) ColorFor(direction calc.Tuple4) Color {
+	return b.Color
+}
+
+//真下がBottom、真上がTopで、その間はrayのyで線形に補間する空
+type GradientBackground struct {
+	Bottom Color
+	Top    Color
+}
+
+var _ Background = GradientBackground{}
+
+func NewGradientBackground(bottom, top Color) GradientBackground {
+	return GradientBackground{
+		Bottom: bottom,
+		Top:    top,
+	}
+}
+
+func (b GradientBackground) ColorFor(direction calc.Tuple4) Color {
+	t := (direction.Normalize()[1] + 1) / 2
+
+	return b.Bottom.MulByScalar(1 - t).Add(b.Top.MulByScalar(t))
+}
+
+//EquirectangularCameraと同じ対応で画像を全方位に貼ったもの
+//画像の中心が-zの向きで、横が経度、縦が緯度
+type EnvironmentMap struct {
+	Image *Canvas
+}
+
+var _ Background = EnvironmentMap{}
+
+func NewEnvironmentMap(image *Canvas) EnvironmentMap {
+	return EnvironmentMap{
+		Image: image,
+	}
+}
+
+func (e EnvironmentMap) ColorFor(direction calc.Tuple4) Color {
+	if e.Image == nil || e.Image.Width == 0 || e.Image.Height == 0 {
+		return Black
+	}
+
+	d := direction.Normalize()
+
+	//EquirectangularCamera.RayForPixelSampleの逆
+	longitude := math.Atan2(-d[0], -d[2])
+	latitude := math.Asin(math.Max(-1, math.Min(1, d[1])))
+
+	u := longitude/(2*math.Pi) + 0.5
+	v := 0.5 - latitude/math.Pi
+
+	return e.sample(u*float64(e.Image.Width)-0.5, v*float64(e.Image.Height)-0.5)
+}
+
+//pixelの中心の間を補間する、横は一周つながっていて縦は端で止める
+func (e EnvironmentMap) sample(x, y float64) Color {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+
+	pixel := func(px, py int) Color {
+		px %= e.Image.Width
+		if px < 0 {
+			px += e.Image.Width
+		}
+
+		if py < 0 {
+			py = 0
+		}
+		if py >= e.Image.Height {
+			py = e.Image.Height - 1
+		}
+
+		return e.Image.Pixels[py][px]
+	}
+
+	ix, iy := int(x0), int(y0)
+	top := pixel(ix, iy).MulByScalar(1 - fx).Add(pixel(ix+1, iy).MulByScalar(fx))
+	bottom := pixel(ix, iy+1).MulByScalar(1 - fx).Add(pixel(ix+1, iy+1).MulByScalar(fx))
+
+	return top.MulByScalar(1 - fy).Add(bottom.MulByScalar(fy))
+}
@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Solid_Background(t *testing.T) {
+	b := NewSolidBackground(Orange)
+	require.Equal(t, Orange, b.ColorFor(calc.NewVector(0, 1, 0)))
+	require.Equal(t, Orange, b.ColorFor(calc.NewVector(1, -2, 3)))
+}
+
+func Test_Gradient_Background(t *testing.T) {
+	b := NewGradientBackground(White, Blue)
+
+	for _, target := range []struct {
+		direction calc.Tuple4
+		ans       Color
+	}{
+		{calc.NewVector(0, 1, 0), Blue},
+		{calc.NewVector(0, -1, 0), White},
+		{calc.NewVector(1, 0, 0), NewColor(0.5, 0.5, 1)},
+		//長さは関係ない
+		{calc.NewVector(0, 0, -5), NewColor(0.5, 0.5, 1)},
+		{calc.NewVector(0, 3, 0), Blue},
+	} {
+		require.True(t, colorCompare(target.ans, b.ColorFor(target.direction)), target)
+	}
+}
+
+func testEnvironmentImage() *Canvas {
+	canvas := NewCanvas(8, 4)
+	for y := 0; y < canvas.Height; y++ {
+		for x := 0; x < canvas.Width; x++ {
+			canvas.WritePixel(x, y, NewColor(float64(x)/8, float64(y)/4, 0.5))
+		}
+	}
+
+	return canvas
+}
+
+//EquirectangularCameraで撮った画像はそのままenvironment mapとして使える
+func Test_Environment_Map_Matches_Equirectangular_Camera(t *testing.T) {
+	image := testEnvironmentImage()
+	env := NewEnvironmentMap(image)
+	camera := NewEquirectangularCamera(8, 4)
+
+	for y := 0; y < image.Height; y++ {
+		for x := 0; x < image.Width; x++ {
+			r, err := camera.RayForPixel(float64(x), float64(y))
+			require.Nil(t, err)
+			require.True(t, colorCompare(image.Pixels[y][x], env.ColorFor(r.Direction)), [2]int{x, y})
+		}
+	}
+}
+
+func Test_Environment_Map_Wraps_Horizontally(t *testing.T) {
+	env := NewEnvironmentMap(testEnvironmentImage())
+
+	//真後ろ(+z)は画像の左端と右端の境目なので両方を半分ずつ混ぜる
+	c := env.ColorFor(calc.NewVector(0, 0, 1))
+	require.True(t, colorCompare(NewColor((0+7.0/8)/2, (0.25+0.5)/2, 0.5), c), c)
+
+	//真上と真下は端のpixelで止まる
+	require.Equal(t, 0.0, env.ColorFor(calc.NewVector(0, 1, 0)).Green)
+	require.Equal(t, 0.75, env.ColorFor(calc.NewVector(0, -1, 0)).Green)
+}
+
+func Test_Environment_Map_Without_Image(t *testing.T) {
+	require.Equal(t, Black, EnvironmentMap{}.ColorFor(calc.NewVector(0, 0, -1)))
+}
+
+//鏡に映るのも背景
+func Test_Reflected_Ray_Uses_Background(t *testing.T) {
+	floor := NewPlane()
+	m := floor.GetMaterial()
+	m.Reflective = 0.5
+	floor.SetMaterial(m)
+	w := NewWorld(NewLight(calc.NewPoint(-10, 10, -10), White), floor)
+
+	r := NewRay(calc.NewPoint(0, 1, -3), calc.NewVector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
+
+	settings := DefaultRenderSettings()
+	black, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+
+	settings.Background = NewGradientBackground(Black, Green)
+	green, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+
+	//反射したrayは斜め45°上に飛ぶ
+	sky := settings.Background.ColorFor(calc.NewVector(0, math.Sqrt(2)/2, math.Sqrt(2)/2))
+	require.True(t, colorCompare(black.Add(sky.MulByScalar(0.5)), green))
+}
+
+//透明な球の向こうに背景が見える
+func Test_Refracted_Ray_Uses_Background(t *testing.T) {
+	ball := NewSphere(1)
+	m := ball.GetMaterial()
+	m.Transparency = 1
+	m.RefractiveIndex = 1.5
+	ball.SetMaterial(m)
+	w := NewWorld(NewLight(calc.NewPoint(-10, 10, -10), White), ball)
+
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+
+	settings := DefaultRenderSettings()
+	black, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+
+	settings.Background = NewSolidBackground(Green)
+	green, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(black.Add(Green), green), green)
+
+	//屈折を追わなければ背景は見えない
+	none, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, 0)
+	require.Nil(t, err)
+	require.True(t, none.Green < black.Green)
+}
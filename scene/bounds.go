@@ -0,0 +1,178 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"rayGo/util"
+)
+
+//axis-aligned bounding box、MinとMaxはPoint
+type BoundingBox struct {
+	Min calc.Tuple4
+	Max calc.Tuple4
+}
+
+func NewBoundingBox(min, max calc.Tuple4) BoundingBox {
+	return BoundingBox{
+		Min: min,
+		Max: max,
+	}
+}
+
+//何も含まないbox、AddPointやMergeで広げていく
+func EmptyBoundingBox() BoundingBox {
+	return BoundingBox{
+		Min: calc.NewPoint(util.Inf, util.Inf, util.Inf),
+		Max: calc.NewPoint(-util.Inf, -util.Inf, -util.Inf),
+	}
+}
+
+func (b BoundingBox) IsEmpty() bool {
+	return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+//PlaneやMin,Maxを指定しないCylinderのように無限に広がるもの
+func (b BoundingBox) IsUnbounded() bool {
+	for i := 0; i < 3; i++ {
+		if math.IsInf(b.Min[i], 0) || math.IsInf(b.Max[i], 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b BoundingBox) AddPoint(p calc.Tuple4) BoundingBox {
+	return BoundingBox{
+		Min: calc.NewPoint(math.Min(b.Min[0], p[0]), math.Min(b.Min[1], p[1]), math.Min(b.Min[2], p[2])),
+		Max: calc.NewPoint(math.Max(b.Max[0], p[0]), math.Max(b.Max[1], p[1]), math.Max(b.Max[2], p[2])),
+	}
+}
+
+func (b BoundingBox) Merge(b2 BoundingBox) BoundingBox {
+	if b2.IsEmpty() {
+		return b
+	}
+
+	return b.AddPoint(b2.Min).AddPoint(b2.Max)
+}
+
+func (b BoundingBox) ContainsPoint(p calc.Tuple4) bool {
+	for i := 0; i < 3; i++ {
+		if p[i] < b.Min[i] || b.Max[i] < p[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b BoundingBox) Centroid() calc.Tuple4 {
+	return calc.NewPoint(
+		(b.Min[0]+b.Max[0])/2,
+		(b.Min[1]+b.Max[1])/2,
+		(b.Min[2]+b.Max[2])/2,
+	)
+}
+
+//無限大の座標に0をかけるとNaNになるので、係数が0の項は足さない
+func transformPoint(mat calc.Mat4x4, p calc.Tuple4) calc.Tuple4 {
+	var temp calc.Tuple4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if mat[i][j] == 0 {
+				continue
+			}
+			temp[i] += mat[i][j] * p[j]
+		}
+	}
+
+	return temp
+}
+
+//8つの角をtransformしてから、それらを含むboxを作り直す
+func (b BoundingBox) Transform(mat calc.Mat4x4) BoundingBox {
+	if b.IsEmpty() {
+		return b
+	}
+
+	ret := EmptyBoundingBox()
+
+	for _, x := range []float64{b.Min[0], b.Max[0]} {
+		for _, y := range []float64{b.Min[1], b.Max[1]} {
+			for _, z := range []float64{b.Min[2], b.Max[2]} {
+				p := transformPoint(mat, calc.NewPoint(x, y, z))
+
+				//inf-infでNaNになった軸はどこまでも広がっている
+				for i := 0; i < 3; i++ {
+					if math.IsNaN(p[i]) {
+						ret.Min[i] = -util.Inf
+						ret.Max[i] = util.Inf
+					}
+				}
+
+				ret = ret.AddPoint(p)
+			}
+		}
+	}
+
+	return ret
+}
+
+func (b BoundingBox) Extent() calc.Tuple4 {
+	return calc.SubTuple(b.Max, b.Min)
+}
+
+func checkBoundsAxis(min, max, originComponent, directionComponent, tolerance float64) (float64, float64) {
+	//軸に平行なrayはslabの中にoriginがあるかどうかだけで決まる
+	if isNearlyZeroWithin(directionComponent, tolerance) {
+		if originComponent < min || max < originComponent {
+			return util.Inf, -util.Inf
+		}
+
+		return -util.Inf, util.Inf
+	}
+
+	tmin := (min - originComponent) / directionComponent
+	tmax := (max - originComponent) / directionComponent
+
+	if tmin > tmax {
+		tmin, tmax = tmax, tmin
+	}
+
+	return tmin, tmax
+}
+
+//Cubeと同じslab法、平べったいTriangleのboxでも取りこぼさないように少し広げて判定する
+func (b BoundingBox) Intersects(r Ray) bool {
+	if b.IsEmpty() {
+		return false
+	}
+
+	tmin, tmax := -util.Inf, util.Inf
+
+	for i := 0; i < 3; i++ {
+		axisMin, axisMax := checkBoundsAxis(
+			b.Min[i]-util.DefaultEpsilon,
+			b.Max[i]+util.DefaultEpsilon,
+			r.Origin[i],
+			r.Direction[i],
+			r.tolerance(),
+		)
+
+		tmin = math.Max(tmin, axisMin)
+		tmax = math.Min(tmax, axisMax)
+
+		if tmin > tmax {
+			return false
+		}
+	}
+
+	//負のtの交点もrefractionのN1,N2の計算に使われるので、boxがrayの後ろにあっても捨てない
+	return true
+}
+
+//shapeのBoundsを親(Groupなど)の空間に変換したもの
+func ParentSpaceBounds(s Shape) BoundingBox {
+	return s.Bounds().Transform(s.GetTransform())
+}
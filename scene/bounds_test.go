@@ -0,0 +1,134 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"rayGo/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Adding_Points_To_Empty_Bounding_Box(t *testing.T) {
+	box := EmptyBoundingBox()
+	require.True(t, box.IsEmpty())
+
+	box = box.AddPoint(calc.NewPoint(-5, 2, 0)).AddPoint(calc.NewPoint(7, 0, -3))
+
+	require.False(t, box.IsEmpty())
+	require.Equal(t, calc.NewPoint(-5, 0, -3), box.Min)
+	require.Equal(t, calc.NewPoint(7, 2, 0), box.Max)
+}
+
+func Test_Shape_Bounds(t *testing.T) {
+	for _, target := range []struct {
+		title string
+		shape Shape
+		min   calc.Tuple4
+		max   calc.Tuple4
+	}{
+		{"sphere", NewSphere(1), calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1)},
+		{"plane", NewPlane(), calc.NewPoint(-util.Inf, 0, -util.Inf), calc.NewPoint(util.Inf, 0, util.Inf)},
+		{"cube", NewCube(), calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1)},
+		{"unbounded cylinder", NewCyliner(), calc.NewPoint(-1, -util.Inf, -1), calc.NewPoint(1, util.Inf, 1)},
+		{"bounded cylinder", NewCyliner(CynMin(-5), CynMax(3)), calc.NewPoint(-1, -5, -1), calc.NewPoint(1, 3, 1)},
+		{"bounded cone", NewCone(ConeMin(-5), ConeMax(3)), calc.NewPoint(-5, -5, -5), calc.NewPoint(5, 3, 5)},
+		{
+			"triangle",
+			NewTriangle(calc.NewPoint(-3, 7, 2), calc.NewPoint(6, 2, -4), calc.NewPoint(2, -1, -1)),
+			calc.NewPoint(-3, -1, -4),
+			calc.NewPoint(6, 7, 2),
+		},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			box := target.shape.Bounds()
+			require.Equal(t, target.min, box.Min)
+			require.Equal(t, target.max, box.Max)
+		})
+	}
+}
+
+func Test_Merge_Bounding_Boxes(t *testing.T) {
+	box1 := NewBoundingBox(calc.NewPoint(-5, -2, 0), calc.NewPoint(7, 4, 4))
+	box2 := NewBoundingBox(calc.NewPoint(8, -7, -2), calc.NewPoint(14, 2, 8))
+
+	box := box1.Merge(box2)
+	require.Equal(t, calc.NewPoint(-5, -7, -2), box.Min)
+	require.Equal(t, calc.NewPoint(14, 4, 8), box.Max)
+
+	require.Equal(t, box1, box1.Merge(EmptyBoundingBox()))
+}
+
+func Test_Bounding_Box_Contains_Point(t *testing.T) {
+	box := NewBoundingBox(calc.NewPoint(5, -2, 0), calc.NewPoint(11, 4, 7))
+
+	require.True(t, box.ContainsPoint(calc.NewPoint(5, -2, 0)))
+	require.True(t, box.ContainsPoint(calc.NewPoint(8, 1, 3)))
+	require.False(t, box.ContainsPoint(calc.NewPoint(3, 0, 3)))
+	require.False(t, box.ContainsPoint(calc.NewPoint(8, 1, 8)))
+}
+
+func Test_Transform_Bounding_Box(t *testing.T) {
+	box := NewBoundingBox(calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1))
+
+	transformed := box.Transform(calc.NewRotateX(math.Pi / 4).MulByMat4x4(calc.NewRotateY(math.Pi / 4)))
+
+	require.True(t, calc.TupleCompare(calc.NewPoint(-1.41421, -1.70710, -1.70710), transformed.Min))
+	require.True(t, calc.TupleCompare(calc.NewPoint(1.41421, 1.70710, 1.70710), transformed.Max))
+}
+
+func Test_Transform_Unbounded_Box(t *testing.T) {
+	//回転したplaneはNaNにならずにどの軸にも無限に広がる
+	box := NewPlane().Bounds().Transform(calc.NewRotateX(math.Pi / 4))
+
+	require.True(t, box.IsUnbounded())
+	require.Equal(t, -util.Inf, box.Min[1])
+	require.Equal(t, util.Inf, box.Max[1])
+
+	//平行移動だけなら広がらない軸はそのまま
+	box = NewPlane().Bounds().Transform(calc.NewTranslation(0, 3, 0))
+	require.Equal(t, 3.0, box.Min[1])
+	require.Equal(t, 3.0, box.Max[1])
+}
+
+func Test_Parent_Space_Bounds(t *testing.T) {
+	s := NewSphere(1)
+	s.SetTransform(calc.NewTranslation(1, -3, 5).MulByMat4x4(calc.NewScale(0.5, 2, 4)))
+
+	box := ParentSpaceBounds(s)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0.5, -5, 1), box.Min))
+	require.True(t, calc.TupleCompare(calc.NewPoint(1.5, -1, 9), box.Max))
+}
+
+func Test_Ray_Intersects_Bounding_Box(t *testing.T) {
+	box := NewBoundingBox(calc.NewPoint(5, -2, 0), calc.NewPoint(11, 4, 7))
+
+	for _, target := range []struct {
+		title     string
+		origin    calc.Tuple4
+		direction calc.Tuple4
+		ans       bool
+	}{
+		{"+x", calc.NewPoint(15, 1, 2), calc.NewVector(-1, 0, 0), true},
+		{"-x", calc.NewPoint(-5, -1, 4), calc.NewVector(1, 0, 0), true},
+		{"+y", calc.NewPoint(7, 6, 5), calc.NewVector(0, -1, 0), true},
+		{"-z", calc.NewPoint(9, 0, -5), calc.NewVector(0, 0, 1), true},
+		{"inside", calc.NewPoint(8, 1, 3.5), calc.NewVector(0, 0, 1), true},
+		{"miss diagonal", calc.NewPoint(9, -1, -8), calc.NewVector(2, 4, 6), false},
+		{"miss parallel", calc.NewPoint(12, 5, 4), calc.NewVector(0, 0, 1), false},
+		{"miss corner", calc.NewPoint(8, 6, -1), calc.NewVector(-1, 0, 0), false},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			r := NewRay(target.origin, target.direction.Normalize())
+			require.Equal(t, target.ans, box.Intersects(r))
+		})
+	}
+}
+
+func Test_Ray_Intersects_Flat_Bounding_Box(t *testing.T) {
+	//xy平面上のtriangleのboxは厚みがない
+	tri := NewTriangle(calc.NewPoint(0, 1, 0), calc.NewPoint(-1, 0, 0), calc.NewPoint(1, 0, 0))
+
+	require.True(t, tri.Bounds().Intersects(NewRay(calc.NewPoint(0, 0.5, -2), calc.NewVector(0, 0, 1))))
+	require.False(t, tri.Bounds().Intersects(NewRay(calc.NewPoint(0, 2, -2), calc.NewVector(0, 0, 1))))
+}
@@ -0,0 +1,157 @@
+package scene
+
+import (
+	"sort"
+)
+
+//leafに入れるshapeの最大数
+const bvhLeafSize = 4
+
+type bvhNode struct {
+	bounds BoundingBox
+	left   *bvhNode
+	right  *bvhNode
+	shapes []Shape
+}
+
+type bvhItem struct {
+	shape    Shape
+	bounds   BoundingBox
+	centroid [3]float64
+}
+
+//Groupの子をまとめたもの、無限に広がるshapeはboxで弾けないのでtreeの外に置く
+type boundingHierarchy struct {
+	bounds    BoundingBox
+	root      *bvhNode
+	unbounded []Shape
+}
+
+func newBoundingHierarchy(shapes []Shape) *boundingHierarchy {
+	h := &boundingHierarchy{
+		bounds: EmptyBoundingBox(),
+	}
+
+	var items []bvhItem
+
+	for _, s := range shapes {
+		b := ParentSpaceBounds(s)
+		h.bounds = h.bounds.Merge(b)
+
+		if b.IsUnbounded() {
+			h.unbounded = append(h.unbounded, s)
+			continue
+		}
+
+		c := b.Centroid()
+		items = append(items, bvhItem{s, b, [3]float64{c[0], c[1], c[2]}})
+	}
+
+	if len(items) != 0 {
+		h.root = buildBVHNode(items)
+	}
+
+	return h
+}
+
+//centroidが一番広がっている軸でsortして中央値で二分割する(median split)
+func buildBVHNode(items []bvhItem) *bvhNode {
+	node := &bvhNode{
+		bounds: EmptyBoundingBox(),
+	}
+
+	centroidBounds := EmptyBoundingBox()
+	for _, item := range items {
+		node.bounds = node.bounds.Merge(item.bounds)
+		centroidBounds = centroidBounds.AddPoint(item.bounds.Centroid())
+	}
+
+	if len(items) <= bvhLeafSize {
+		for _, item := range items {
+			node.shapes = append(node.shapes, item.shape)
+		}
+		return node
+	}
+
+	extent := centroidBounds.Extent()
+	axis := 0
+	if extent[1] > extent[axis] {
+		axis = 1
+	}
+	if extent[2] > extent[axis] {
+		axis = 2
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].centroid[axis] < items[j].centroid[axis]
+	})
+
+	mid := len(items) / 2
+	node.left = buildBVHNode(items[:mid])
+	node.right = buildBVHNode(items[mid:])
+
+	return node
+}
+
+func (n *bvhNode) intersect(r Ray, xs []*Intersection) ([]*Intersection, error) {
+	if !n.bounds.Intersects(r) {
+		return xs, nil
+	}
+
+	for _, s := range n.shapes {
+		section, err := s.Intersect(r)
+		if err != nil {
+			return nil, err
+		}
+
+		xs = append(xs, section.Intersections...)
+	}
+
+	for _, child := range []*bvhNode{n.left, n.right} {
+		if child == nil {
+			continue
+		}
+
+		var err error
+		xs, err = child.intersect(r, xs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return xs, nil
+}
+
+func (h *boundingHierarchy) intersect(r Ray) ([]*Intersection, error) {
+	var xs []*Intersection
+
+	for _, s := range h.unbounded {
+		section, err := s.Intersect(r)
+		if err != nil {
+			return nil, err
+		}
+
+		xs = append(xs, section.Intersections...)
+	}
+
+	if h.root == nil {
+		return xs, nil
+	}
+
+	return h.root.intersect(r, xs)
+}
+
+//子のtransformが変わったら親のboxを作り直す必要がある
+type boundsInvalidator interface {
+	invalidateBounds()
+}
+
+func invalidateParentBounds(s Shape) {
+	if s == nil {
+		return
+	}
+
+	if invalidator, ok := s.(boundsInvalidator); ok {
+		invalidator.invalidateBounds()
+	}
+}
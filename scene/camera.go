@@ -5,14 +5,78 @@ import (
 	"rayGo/calc"
 )
 
+//World.Renderに渡せるcamera
+//どのcameraもcamera空間では-zを向いていて、ViewTransformで置く場所と向きを決める
+type Projector interface {
+	//描画するCanvasの幅と高さ
+	CanvasSize() (width, height int)
+	RayForPixelSample(px, py float64, sample PixelSample) (Ray, error)
+}
+
+var _ Projector = Camera{}
+
+//全てのcameraで共通のTransform
+type viewTransform struct {
+	Transform calc.Mat4x4
+	//SetTransformで計算したinverseと、そのときのTransform
+	inverse           calc.Mat4x4
+	invertedTransform calc.Mat4x4
+}
+
+func newViewTransform() viewTransform {
+	return viewTransform{
+		Transform:         calc.Ident4x4,
+		inverse:           calc.Ident4x4,
+		invertedTransform: calc.Ident4x4,
+	}
+}
+
+//逆行列を持たないtransformはここでerrorを返し、元のtransformのままにする
+func (v *viewTransform) SetTransform(mat calc.Mat4x4) error {
+	inv, err := mat.Inverse()
+	if err != nil {
+		return err
+	}
+
+	v.Transform = mat
+	v.inverse = inv
+	v.invertedTransform = mat
+
+	return nil
+}
+
+//Transformが直接書き換えられていたらその場で計算し直す
+func (v viewTransform) inverseTransform() (calc.Mat4x4, error) {
+	if v.Transform == v.invertedTransform {
+		return v.inverse, nil
+	}
+
+	return v.Transform.Inverse()
+}
+
+//camera空間のoriginとdirectionをworld空間のrayにする
+func (v viewTransform) cameraRay(origin, direction calc.Tuple4) (Ray, error) {
+	inv, err := v.inverseTransform()
+	if err != nil {
+		return Ray{}, err
+	}
+
+	return NewRay(inv.MulByTuple(origin), inv.MulByTuple(direction).Normalize()), nil
+}
+
+//透視投影のcamera
 type Camera struct {
 	HSize       float64
 	VSize       float64
 	FieldOfView float64
-	Transform   calc.Mat4x4
-	HalfHeight  float64
-	HalfWidth   float64
-	PixelSize   float64
+	viewTransform
+	HalfHeight float64
+	HalfWidth  float64
+	PixelSize  float64
+	//lensの半径、0ならpinhole cameraで全てのrayが一点から出る
+	Aperture float64
+	//pinholeから見てピントが合う平面までの距離
+	FocalDistance float64
 }
 
 func calcHalfWidthAndHeight(hSize, vSize, fieldOfView float64) (half_width, half_height float64) {
@@ -31,13 +95,43 @@ func calcHalfWidthAndHeight(hSize, vSize, fieldOfView float64) (half_width, half
 
 }
 
-func NewCamera(hSize, vSize, fieldOfView float64) Camera {
+type CameraOptions struct {
+	Aperture      float64
+	FocalDistance float64
+}
+
+type CameraOption func(*CameraOptions)
+
+func CameraAperture(radius float64) CameraOption {
+	return func(o *CameraOptions) {
+		o.Aperture = radius
+	}
+}
+
+func CameraFocalDistance(distance float64) CameraOption {
+	return func(o *CameraOptions) {
+		o.FocalDistance = distance
+	}
+}
+
+func NewCamera(hSize, vSize, fieldOfView float64, options ...CameraOption) Camera {
+
+	defaultOptions := &CameraOptions{
+		0,
+		1,
+	}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
 
 	camera := Camera{
-		HSize:       hSize,
-		VSize:       vSize,
-		FieldOfView: fieldOfView,
-		Transform:   calc.Ident4x4,
+		HSize:         hSize,
+		VSize:         vSize,
+		FieldOfView:   fieldOfView,
+		viewTransform: newViewTransform(),
+		Aperture:      defaultOptions.Aperture,
+		FocalDistance: defaultOptions.FocalDistance,
 	}
 
 	half_width, half_height := calcHalfWidthAndHeight(hSize, vSize, fieldOfView)
@@ -49,18 +143,32 @@ func NewCamera(hSize, vSize, fieldOfView float64) Camera {
 	return camera
 }
 
+func (c Camera) CanvasSize() (width, height int) {
+	return int(c.HSize), int(c.VSize)
+}
+
+//pixelの中心を通るray
 func (c Camera) RayForPixel(px, py float64) (Ray, error) {
-	x_offset := (px + 0.5) * c.PixelSize
-	y_offset := (py + 0.5) * c.PixelSize
+	return c.RayForPixelSample(px, py, PixelCenter)
+}
+
+//pixelの中のsampleの位置を通るray、Samplerから使う
+func (c Camera) RayForPixelSample(px, py float64, sample PixelSample) (Ray, error) {
+	x_offset := (px + sample.X) * c.PixelSize
+	y_offset := (py + sample.Y) * c.PixelSize
 
 	world_x := c.HalfWidth - x_offset
 	world_y := c.HalfHeight - y_offset
 
-	cameraTransInv, err := c.Transform.Inverse()
+	cameraTransInv, err := c.inverseTransform()
 	if err != nil {
 		return Ray{}, err
 	}
 
+	if c.Aperture > 0 {
+		return c.thinLensRay(world_x, world_y, sample, cameraTransInv), nil
+	}
+
 	pixel := cameraTransInv.MulByTuple(calc.NewPoint(world_x, world_y, -1))
 	origin := cameraTransInv.MulByTuple(calc.NewPoint(0, 0, 0))
 	direction := calc.SubTuple(pixel, origin).Normalize()
@@ -69,6 +177,22 @@ func (c Camera) RayForPixel(px, py float64) (Ray, error) {
 
 }
 
+//pinholeからpixelを通る線がfocal planeと交わる点には、lensのどこから出たrayも集まる
+//lensの上の点は半径Apertureの円盤にLensU,LensVを一様に散らして決める
+func (c Camera) thinLensRay(world_x, world_y float64, sample PixelSample, cameraTransInv calc.Mat4x4) Ray {
+	focal := calc.NewPoint(world_x*c.FocalDistance, world_y*c.FocalDistance, -c.FocalDistance)
+
+	r := c.Aperture * math.Sqrt(sample.LensU)
+	theta := 2 * math.Pi * sample.LensV
+	lens := calc.NewPoint(r*math.Cos(theta), r*math.Sin(theta), 0)
+
+	origin := cameraTransInv.MulByTuple(lens)
+	target := cameraTransInv.MulByTuple(focal)
+	direction := calc.SubTuple(target, origin).Normalize()
+
+	return NewRay(origin, direction)
+}
+
 //ViewTransformの導出はPDFに書いてある
 func ViewTransform(from, to, up calc.Tuple4) calc.Mat4x4 {
 	forward := calc.SubTuple(to, from).Normalize()
@@ -3,6 +3,7 @@ package scene
 import (
 	"math"
 	"rayGo/calc"
+	"rayGo/util"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -122,6 +123,22 @@ func Test_Ray_For_Pixel(t *testing.T) {
 
 }
 
+func Test_Camera_SetTransform(t *testing.T) {
+	camera := NewCamera(201, 101, math.Pi/2)
+	require.Nil(t, camera.SetTransform(calc.NewRotateY(math.Pi/4).MulByMat4x4(calc.NewTranslation(0, -2, 5))))
+
+	ray, err := camera.RayForPixel(100, 50)
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0, 2, -5), ray.Origin))
+	require.True(t, calc.TupleCompare(calc.NewVector(math.Sqrt(2)/2, 0, -math.Sqrt(2)/2), ray.Direction))
+
+	//逆行列を持たないtransformは設定時にerrorになり、元のtransformのまま
+	require.NotNil(t, camera.SetTransform(calc.NewScale(0, 0, 0)))
+	ray, err = camera.RayForPixel(100, 50)
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0, 2, -5), ray.Origin))
+}
+
 func Test_Render_World_With_Camera(t *testing.T) {
 	w := DefaultWorld()
 	camera := NewCamera(11, 11, math.Pi/2)
@@ -129,7 +146,7 @@ func Test_Render_World_With_Camera(t *testing.T) {
 	to := calc.NewPoint(0, 0, 0)
 	up := calc.NewVector(0, 1, 0)
 
-	camera.Transform = ViewTransform(from, to, up)
+	camera.SetTransform(ViewTransform(from, to, up))
 
 	canvas, err := w.Render(camera)
 	require.Nil(t, err)
@@ -137,3 +154,97 @@ func Test_Render_World_With_Camera(t *testing.T) {
 	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), canvas.Pixels[5][5]))
 
 }
+
+func Test_Ray_For_Pixel_Sample(t *testing.T) {
+	camera := NewCamera(201, 101, math.Pi/2)
+
+	//中心のsampleはRayForPixelと同じ
+	center, err := camera.RayForPixelSample(100, 50, PixelCenter)
+	require.Nil(t, err)
+	ray, err := camera.RayForPixel(100, 50)
+	require.Nil(t, err)
+	require.Equal(t, ray, center)
+
+	//pixel(99,49)の右下の角は(100,50)の左上の角と同じ
+	corner1, err := camera.RayForPixelSample(99, 49, PixelSample{X: 1, Y: 1})
+	require.Nil(t, err)
+	corner2, err := camera.RayForPixelSample(100, 50, PixelSample{X: 0, Y: 0})
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(corner1.Direction, corner2.Direction))
+}
+
+func Test_Thin_Lens_Camera(t *testing.T) {
+	camera := NewCamera(201, 101, math.Pi/2, CameraAperture(0.5), CameraFocalDistance(4))
+	require.Equal(t, 0.5, camera.Aperture)
+	require.Equal(t, 4.0, camera.FocalDistance)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	pinhole := NewCamera(201, 101, math.Pi/2)
+	require.Equal(t, 0.0, pinhole.Aperture)
+	require.Equal(t, 1.0, pinhole.FocalDistance)
+	require.Nil(t, pinhole.SetTransform(camera.Transform))
+
+	center, err := pinhole.RayForPixelSample(70, 30, PixelCenter)
+	require.Nil(t, err)
+	//cameraは+zを向いているので、focal planeはz=-5+4
+	focal := center.Position(4 / center.Direction[2])
+
+	//lensのどこから出たrayもfocal plane上の同じ点を通る
+	for _, lens := range [][2]float64{{0, 0}, {0.5, 0.25}, {1, 0.5}, {0.3, 0.9}} {
+		ray, err := camera.RayForPixelSample(70, 30, PixelSample{0.5, 0.5, lens[0], lens[1]})
+		require.Nil(t, err)
+
+		//originはlensの円盤の中、camera空間のz=0はworldのz=-5
+		require.True(t, calc.SubTuple(ray.Origin, calc.NewPoint(0, 0, -5)).Magnitude() <= 0.5+0.00001)
+		require.True(t, util.FloatEqual(-5, ray.Origin[2]))
+
+		toFocal := calc.SubTuple(focal, ray.Origin)
+		require.True(t, calc.TupleCompare(toFocal.Normalize(), ray.Direction))
+	}
+}
+
+func Test_Thin_Lens_Center_Matches_Pinhole(t *testing.T) {
+	camera := NewCamera(11, 11, math.Pi/2, CameraAperture(0.2), CameraFocalDistance(5))
+	pinhole := NewCamera(11, 11, math.Pi/2)
+
+	//lensの中心(LensU=0)から出るrayはpinholeのrayと同じ
+	ray, err := camera.RayForPixelSample(3, 8, PixelSample{0.5, 0.5, 0, 0})
+	require.Nil(t, err)
+	ans, err := pinhole.RayForPixel(3, 8)
+	require.Nil(t, err)
+
+	require.True(t, calc.TupleCompare(ans.Origin, ray.Origin))
+	require.True(t, calc.TupleCompare(ans.Direction, ray.Direction))
+}
+
+func Test_Render_With_Depth_Of_Field(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(21, 21, math.Pi/2, CameraAperture(0.3), CameraFocalDistance(2))
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	c1, err := w.Render(camera, RenderSampler(NewJitteredSampler(2)), RenderSeed(3))
+	require.Nil(t, err)
+
+	c2, err := w.Render(camera, RenderSampler(NewJitteredSampler(2)), RenderSeed(3), RenderWorkers(1))
+	require.Nil(t, err)
+	require.Equal(t, c1.Pixels, c2.Pixels)
+
+	pinhole := camera
+	pinhole.Aperture = 0
+	sharp, err := w.Render(pinhole, RenderSampler(NewJitteredSampler(2)), RenderSeed(3))
+	require.Nil(t, err)
+	require.NotEqual(t, sharp.Pixels, c1.Pixels)
+}
+
+func Test_Camera_Canvas_Size(t *testing.T) {
+	camera := NewCamera(160, 120, math.Pi/2)
+
+	w, h := camera.CanvasSize()
+	require.Equal(t, 160, w)
+	require.Equal(t, 120, h)
+
+	canvas, err := DefaultWorld().Render(camera)
+	require.Nil(t, err)
+	require.Equal(t, 160, canvas.Width)
+	require.Equal(t, 120, canvas.Height)
+}
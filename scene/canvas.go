@@ -1,9 +1,7 @@
 package scene
 
 import (
-	"fmt"
 	"math"
-	"strconv"
 	"strings"
 )
 
@@ -42,67 +40,35 @@ var maxColorValue int = 255
 var minColorValue int = 0
 
 func getColorValue(c float64) int {
+	return scaleColorValue(c, maxColorValue)
+}
 
-	target := int(math.Ceil(float64(maxColorValue) * c))
-	if target <= minColorValue {
+//0~1のcを0~maxValueに切り上げで変換してはみ出た分は切り捨てる
+//Transferを指定しないときの書き出しはずっと切り上げだったので、既存の画像が変わらないようにそのままにする
+//四捨五入にしたいときはImageTransfer(LinearTransfer{})を渡す
+func scaleColorValue(c float64, maxValue int) int {
+	//NaNをintにしたときの値は決まっていないのでclamp01と同じく0にする
+	if math.IsNaN(c) {
 		return minColorValue
 	}
 
-	if maxColorValue <= target {
-		return maxColorValue
+	target := int(math.Ceil(float64(maxValue) * c))
+	if target <= minColorValue {
+		return minColorValue
 	}
 
-	return target
-}
-
-func (c *Canvas) createWidthString(i int) string {
-	var buf strings.Builder
-
-	//red ,green,blueとプラスしていくときにプラスした時点で70charを超えたら改行
-	//スペースなしで70越えなければOK
-	written := 0
-	threshold := 69
-
-	for j := 0; j < c.Width; j++ {
-		target := c.Pixels[i][j]
-		for i := 0; i <= 2; i++ {
-			value := getColorValue(target.GetByIndex(i))
-
-			if written+3 > threshold {
-				//70に収まらないとき
-				buf.WriteString("\n")
-				buf.WriteString(strconv.Itoa(value))
-				buf.WriteString(" ")
-				written = 4
-				continue
-			}
-
-			//通常
-			buf.WriteString(strconv.Itoa(value))
-			if written+6 < threshold {
-				buf.WriteString(" ")
-			}
-
-			written += 4
-		}
-
+	if maxValue <= target {
+		return maxValue
 	}
 
-	return buf.String()
+	return target
 }
 
 func (c *Canvas) ToPPM() string {
 	var buf strings.Builder
 
-	buf.WriteString("P3\n")
-	buf.WriteString(fmt.Sprintf("%d %d\n", c.Width, c.Height))
-	buf.WriteString(fmt.Sprintf("%d\n", maxColorValue))
-
-	for i := 0; i < c.Height; i++ {
-		widthString := strings.TrimSuffix(c.createWidthString(i), " ")
-		buf.WriteString(widthString)
-		buf.WriteString("\n")
-	}
+	//strings.Builderへの書き込みは失敗しない
+	c.WritePPM(&buf)
 
 	return buf.String()
 
@@ -0,0 +1,250 @@
+package scene
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ImageFormatError struct {
+	message string
+}
+
+func (i ImageFormatError) Error() string {
+	return i.message
+}
+
+func NewImageFormatError(message string) ImageFormatError {
+	return ImageFormatError{
+		message: message,
+	}
+}
+
+type ImageOptions struct {
+	//PPMの1channelの最大値、256以上にするとP6では2byteで書き出す
+	MaxValue int
+	//.ppmをSaveするときにP6(binary)で書き出す
+	BinaryPPM bool
+	ToneMap   ToneMapper
+	//指定しないときは今までどおりlinearのまま切り上げる
+	Transfer Transfer
+}
+
+type ImageOption func(*ImageOptions)
+
+func ImageMaxValue(maxValue int) ImageOption {
+	return func(o *ImageOptions) {
+		o.MaxValue = maxValue
+	}
+}
+
+func ImageBinaryPPM(binary bool) ImageOption {
+	return func(o *ImageOptions) {
+		o.BinaryPPM = binary
+	}
+}
+
+//8bitなどの整数の形式に書き出す前に明るさを0~1に収める、PFMやHDRには使わない
+func ImageToneMap(toneMap ToneMapper) ImageOption {
+	return func(o *ImageOptions) {
+		o.ToneMap = toneMap
+	}
+}
+
+//書き出すときはencode、LoadCanvasやReadPPMではdecodeに使う
+//指定すると切り上げではなく四捨五入になる
+func ImageTransfer(transfer Transfer) ImageOption {
+	return func(o *ImageOptions) {
+		o.Transfer = transfer
+	}
+}
+
+func newImageOptions(options ...ImageOption) *ImageOptions {
+	defaultOptions := &ImageOptions{
+		maxColorValue,
+		false,
+		nil,
+		nil,
+	}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
+
+	return defaultOptions
+}
+
+func (o *ImageOptions) validateMaxValue() error {
+	if o.MaxValue < 1 || maxPPMValue < o.MaxValue {
+		return NewImageFormatError(fmt.Sprintf("maxval must be between 1 and %d, got %d", maxPPMValue, o.MaxValue))
+	}
+
+	return nil
+}
+
+//tone mapしてから各channelを0~MaxValueの整数にする
+func (o *ImageOptions) quantize(c Color) [3]int {
+	if o.ToneMap != nil {
+		c = o.ToneMap.Map(c)
+	}
+
+	if o.Transfer != nil {
+		return [3]int{
+			encodeColorValue(c.Red, o.MaxValue, o.Transfer),
+			encodeColorValue(c.Green, o.MaxValue, o.Transfer),
+			encodeColorValue(c.Blue, o.MaxValue, o.Transfer),
+		}
+	}
+
+	return [3]int{
+		scaleColorValue(c.Red, o.MaxValue),
+		scaleColorValue(c.Green, o.MaxValue),
+		scaleColorValue(c.Blue, o.MaxValue),
+	}
+}
+
+//ToPPMと同じく0~255に収める、MaxValueは使わない
+func (c *Canvas) Image(options ...ImageOption) image.Image {
+	opts := newImageOptions(options...)
+	opts.MaxValue = maxColorValue
+
+	img := image.NewNRGBA(image.Rect(0, 0, c.Width, c.Height))
+
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			values := opts.quantize(c.Pixels[y][x])
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(values[0]),
+				G: uint8(values[1]),
+				B: uint8(values[2]),
+				A: 255,
+			})
+		}
+	}
+
+	return img
+}
+
+func (c *Canvas) WritePNG(w io.Writer, options ...ImageOption) error {
+	return png.Encode(w, c.Image(options...))
+}
+
+//拡張子(.ppm, .png, .pfm, .hdr)で形式を選んで書き出す
+//Save("test.png")とすればPNGになり、知らない拡張子はImageFormatErrorを返す
+//.ppmはImageBinaryPPM(true)のときP6、それ以外はP3
+func (c *Canvas) Save(path string, options ...ImageOption) (err error) {
+	opts := newImageOptions(options...)
+
+	var write func(io.Writer) error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ppm":
+		if err := opts.validateMaxValue(); err != nil {
+			return err
+		}
+
+		write = func(w io.Writer) error {
+			if opts.BinaryPPM {
+				return c.WriteBinaryPPM(w, options...)
+			}
+
+			return c.WritePPM(w, options...)
+		}
+	case ".png":
+		write = func(w io.Writer) error {
+			return c.WritePNG(w, options...)
+		}
+	case ".pfm":
+		write = c.WritePFM
+	case ".hdr":
+		write = c.WriteHDR
+	default:
+		return NewImageFormatError("unsupported image format: " + path)
+	}
+
+	fp, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := fp.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return write(fp)
+}
+
+//PNGやJPEGなどimage.Imageを0~1のCanvasにする、ImageTransferを指定するとそのdecodeでlinearに戻す
+//alphaは捨てるが、半透明のpixelは色をalphaで割って元の色に戻してから使う
+func CanvasFromImage(img image.Image, options ...ImageOption) *Canvas {
+	opts := newImageOptions(options...)
+	transfer := opts.Transfer
+	if transfer == nil {
+		transfer = LinearTransfer{}
+	}
+
+	bounds := img.Bounds()
+	canvas := NewCanvas(bounds.Dx(), bounds.Dy())
+
+	for y := 0; y < canvas.Height; y++ {
+		for x := 0; x < canvas.Width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if a == 0 {
+				continue
+			}
+
+			//RGBAはalphaを掛けた0~65535の値
+			alpha := float64(a)
+			canvas.WritePixel(x, y, NewColor(
+				transfer.Decode(float64(r)/alpha),
+				transfer.Decode(float64(g)/alpha),
+				transfer.Decode(float64(b)/alpha),
+			))
+		}
+	}
+
+	return canvas
+}
+
+//拡張子で形式を選んで読み込む
+func LoadCanvas(path string, options ...ImageOption) (*Canvas, error) {
+	var decode func(io.Reader) (image.Image, error)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ppm":
+		fp, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer fp.Close()
+
+		return ReadPPM(fp, options...)
+	case ".png":
+		decode = png.Decode
+	case ".jpg", ".jpeg":
+		decode = jpeg.Decode
+	default:
+		return nil, NewImageFormatError("unsupported image format: " + path)
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	img, err := decode(fp)
+	if err != nil {
+		return nil, NewImageFormatError(fmt.Sprintf("%s: %v", path, err))
+	}
+
+	return CanvasFromImage(img, options...), nil
+}
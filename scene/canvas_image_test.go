@@ -0,0 +1,75 @@
+package scene
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Canvas_To_Image(t *testing.T) {
+	c := NewCanvas(5, 3)
+	c.WritePixel(0, 0, NewColor(1.5, 0, 0))
+	c.WritePixel(2, 1, NewColor(0, 0.5, 0))
+	c.WritePixel(4, 2, NewColor(-0.5, 0, 1))
+
+	img := c.Image()
+	require.Equal(t, 5, img.Bounds().Dx())
+	require.Equal(t, 3, img.Bounds().Dy())
+
+	//ToPPMと同じclamp
+	require.Equal(t, color.NRGBA{255, 0, 0, 255}, img.At(0, 0))
+	require.Equal(t, color.NRGBA{0, 128, 0, 255}, img.At(2, 1))
+	require.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(4, 2))
+	require.Equal(t, color.NRGBA{0, 0, 0, 255}, img.At(1, 1))
+}
+
+func Test_Canvas_Write_PNG(t *testing.T) {
+	c := NewCanvas(4, 2)
+	c.WritePixel(3, 1, NewColor(0.2, 0.4, 0.6))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePNG(&buf))
+
+	img, err := png.Decode(&buf)
+	require.Nil(t, err)
+
+	r, g, b, _ := img.At(3, 1).RGBA()
+	require.Equal(t, []uint32{51, 102, 153}, []uint32{r >> 8, g >> 8, b >> 8})
+}
+
+func Test_Canvas_Save_By_Extension(t *testing.T) {
+	dir, err := ioutil.TempDir("", "canvas")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewCanvas(5, 3)
+	c.WritePixel(0, 0, NewColor(1.5, 0, 0))
+
+	ppmPath := filepath.Join(dir, "out.ppm")
+	require.Nil(t, c.Save(ppmPath))
+	ppm, err := ioutil.ReadFile(ppmPath)
+	require.Nil(t, err)
+	require.Equal(t, c.ToPPM(), string(ppm))
+
+	require.Nil(t, c.Save(ppmPath, ImageBinaryPPM(true)))
+	p6, err := ioutil.ReadFile(ppmPath)
+	require.Nil(t, err)
+	require.Equal(t, "P6\n5 3\n255\n", string(p6[:11]))
+
+	pngPath := filepath.Join(dir, "out.PNG")
+	require.Nil(t, c.Save(pngPath))
+	fp, err := os.Open(pngPath)
+	require.Nil(t, err)
+	defer fp.Close()
+	_, err = png.Decode(fp)
+	require.Nil(t, err)
+
+	err = c.Save(filepath.Join(dir, "out.gif"))
+	require.IsType(t, ImageFormatError{}, err)
+}
@@ -59,3 +59,24 @@ func TestPPMLongLine(t *testing.T) {
 
 	require.Equal(t, target, c.ToPPM())
 }
+
+func Test_Scale_Color_Value_Rounds_Up(t *testing.T) {
+	for _, target := range []struct {
+		c       float64
+		ceil    int
+		rounded int
+	}{
+		{0, 0, 0},
+		{0.001, 1, 0},
+		{0.2, 51, 51},
+		{100.2 / 255, 101, 100},
+		{0.5, 128, 128},
+		{1, 255, 255},
+		{-0.1, 0, 0},
+		{1.1, 255, 255},
+	} {
+		//Transferを指定しないと切り上げ、指定すると四捨五入
+		require.Equal(t, target.ceil, scaleColorValue(target.c, 255), target.c)
+		require.Equal(t, target.rounded, encodeColorValue(target.c, 255, LinearTransfer{}), target.c)
+	}
+}
@@ -6,7 +6,8 @@ import (
 	"rayGo/util"
 )
 
-//TODO:UVマッピングを実装してSphereへの貼り付けを完全なものにする
+//3次元の点で決まる市松模様なので、Sphereなどの曲面に貼ると升目が歪む
+//曲面にはUVCheckersをNewTextureMapPatternで貼る
 type CheckersPattern struct {
 	*BasePattern
 	Color1 Color
@@ -50,6 +50,10 @@ func (c Color) Sub(c2 Color) Color {
 	return NewColor(r, g, b)
 }
 
+func (c Color) MulByScalar(s float64) Color {
+	return NewColor(c.Red*s, c.Green*s, c.Blue*s)
+}
+
 func (c Color) ToTuple4() calc.Tuple4 {
 	return calc.Tuple4{
 		c.Red,
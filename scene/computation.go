@@ -18,6 +18,20 @@ type PreComps struct {
 	IsRayInside bool
 	N1          float64
 	N2          float64
+	TexCoord    TexCoord
+	HasTexCoord bool
+}
+
+//texture座標を持つhitならLightingでpatternが使えるようにObjectに付けて渡す
+func (comps PreComps) shadingObject() Shape {
+	if !comps.HasTexCoord {
+		return comps.Object
+	}
+
+	return texCoordHitShape{
+		comps.Object,
+		comps.TexCoord,
+	}
 }
 
 func isTotalInternalReflection(n1, n2, sin2_t float64) bool {
@@ -108,6 +122,11 @@ func findN1AndN2(xs Intersections, target Intersection) (N1, N2 float64) {
 }
 
 func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) (PreComps, error) {
+	return prepareComputations(intersection, ray, xs, util.DefaultEpsilon)
+}
+
+//biasはOverPoint,UnderPointを面からずらす距離
+func prepareComputations(intersection Intersection, ray Ray, xs Intersections, bias float64) (PreComps, error) {
 
 	t := intersection.Time
 	obj := intersection.Object
@@ -130,11 +149,13 @@ func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) (
 	reflect_vec := calc.Reflect(ray.Direction, normal_vec)
 
 	//shadow用にOverPointを作る,normal方向に微小に↓にずらしたものがoverpoint
-	over_point := calc.AddTuple(ray_point, calc.MulTupleByScalar(util.DefaultEpsilon, normal_vec))
-	under_point := calc.SubTuple(ray_point, calc.MulTupleByScalar(util.DefaultEpsilon, normal_vec))
+	over_point := calc.AddTuple(ray_point, calc.MulTupleByScalar(bias, normal_vec))
+	under_point := calc.SubTuple(ray_point, calc.MulTupleByScalar(bias, normal_vec))
 
 	n1, n2 := findN1AndN2(xs, intersection)
 
+	texCoord, hasTexCoord := hitTexCoordOf(intersection)
+
 	return PreComps{
 		Time:        t,
 		Object:      obj,
@@ -147,5 +168,7 @@ func PrepareComputations(intersection Intersection, ray Ray, xs Intersections) (
 		IsRayInside: IsRayInside,
 		N1:          n1,
 		N2:          n2,
+		TexCoord:    texCoord,
+		HasTexCoord: hasTexCoord,
 	}, nil
 }
@@ -118,7 +118,7 @@ func (c Cone) calcXs(ray Ray, ts []float64) Intersections {
 
 func (c Cone) intersectCaps(ray Ray, sections []*Intersection) Intersections {
 
-	if !c.Closed || util.IsNearlyEqualZero(ray.Direction[1]) {
+	if !c.Closed || ray.isNearlyZero(ray.Direction[1]) {
 		return AggregateIntersection(sections...)
 	}
 
@@ -140,9 +140,9 @@ func (c Cone) checkCap(ray Ray, t, y float64) bool {
 	return (math.Pow(x, 2) + math.Pow(z, 2)) <= math.Abs(y)
 }
 
-func calcTimes(a, b, c, ans float64) []float64 {
+func calcTimes(a, b, c, ans, tolerance float64) []float64 {
 
-	if util.IsNearlyEqualZero(a) && !util.IsNearlyEqualZero(b) {
+	if isNearlyZeroWithin(a, tolerance) && !isNearlyZeroWithin(b, tolerance) {
 		t := -c / (2.0 * b)
 		return []float64{t}
 	}
@@ -160,7 +160,7 @@ func (c Cone) calcLocalIntersect(r Ray) (Intersections, error) {
 	_b := 2*(r.Origin[0]*r.Direction[0]) - 2*(r.Origin[1]*r.Direction[1]) + 2*(r.Origin[2]*r.Direction[2])
 	_c := math.Pow(r.Origin[0], 2) - math.Pow(r.Origin[1], 2) + math.Pow(r.Origin[2], 2)
 
-	if util.IsNearlyEqualZero(_a) && util.IsNearlyEqualZero(_b) {
+	if r.isNearlyZero(_a) && r.isNearlyZero(_b) {
 		return Intersections{}, nil
 	}
 
@@ -170,7 +170,7 @@ func (c Cone) calcLocalIntersect(r Ray) (Intersections, error) {
 		return Intersections{}, nil
 	}
 
-	ts := calcTimes(_a, _b, _c, ans)
+	ts := calcTimes(_a, _b, _c, ans, r.tolerance())
 
 	xs := c.calcXs(r, ts)
 
@@ -192,3 +192,9 @@ func (c Cone) SetMaterial(m *Material) {
 func (c Cone) IsInclude(s Shape) bool {
 	return c == s
 }
+
+//coneの半径はyの絶対値と同じなので、Min,Maxの大きい方で囲む
+func (c Cone) Bounds() BoundingBox {
+	limit := math.Max(math.Abs(c.Min), math.Abs(c.Max))
+	return NewBoundingBox(calc.NewPoint(-limit, c.Min, -limit), calc.NewPoint(limit, c.Max, limit))
+}
@@ -2,6 +2,7 @@ package scene
 
 import (
 	"rayGo/calc"
+	"sync/atomic"
 )
 
 type CSGError struct {
@@ -18,11 +19,14 @@ func NewCSGError(msg string) CSGError {
 	}
 }
 
+//LeftとRightは直接差し替えないこと(boxが作り直されない)
 type CSG struct {
 	*BaseShape
 	Operation string
 	Left      Shape
 	Right     Shape
+	//rayごとに計算し直さないようにoperandのboxをまとめたものを持っておく
+	bounds atomic.Value
 }
 
 var _ Shape = &CSG{}
@@ -49,10 +53,10 @@ func NewCSG(operation string, left, right Shape) (*CSG, error) {
 		return nil, NewCSGError("operation is invalid")
 	}
 	csg := &CSG{
-		NewBaseShape(),
-		operation,
-		left,
-		right,
+		BaseShape: NewBaseShape(),
+		Operation: operation,
+		Left:      left,
+		Right:     right,
 	}
 
 	csg.setParentToShapes(left, right)
@@ -120,6 +124,11 @@ func (c *CSG) NormalAt(worldPoint calc.Tuple4, hit Intersection) (calc.Tuple4, e
 }
 
 func (c *CSG) calcLocalIntersect(r Ray) (Intersections, error) {
+	//operandのどちらのboxにも当たらなければfilterするまでもない
+	if !c.Bounds().Intersects(r) {
+		return Intersections{}, nil
+	}
+
 	leftXs, err := c.Left.Intersect(r)
 	if err != nil {
 		return Intersections{}, err
@@ -140,19 +149,19 @@ func (c *CSG) calcLocalIntersect(r Ray) (Intersections, error) {
 	return c.filterIntersections(xs), nil
 }
 
-func (c CSG) Intersect(r Ray) (Intersections, error) {
+func (c *CSG) Intersect(r Ray) (Intersections, error) {
 	return c.ShapeIntersect(r, c.calcLocalIntersect)
 }
 
-func (c CSG) GetMaterial() *Material {
+func (c *CSG) GetMaterial() *Material {
 	return c.Material
 }
 
-func (c CSG) SetMaterial(m *Material) {
+func (c *CSG) SetMaterial(m *Material) {
 	c.Material = m
 }
 
-func (c CSG) IsInclude(s Shape) bool {
+func (c *CSG) IsInclude(s Shape) bool {
 
 	for _, child := range []Shape{
 		c.Left,
@@ -165,3 +174,26 @@ func (c CSG) IsInclude(s Shape) bool {
 
 	return false
 }
+
+func (c *CSG) loadBounds() *BoundingBox {
+	b, _ := c.bounds.Load().(*BoundingBox)
+	return b
+}
+
+//並列Render中に同時に計算されても同じboxになるのでlockはしない
+func (c *CSG) Bounds() BoundingBox {
+	if b := c.loadBounds(); b != nil {
+		return *b
+	}
+
+	b := ParentSpaceBounds(c.Left).Merge(ParentSpaceBounds(c.Right))
+	c.bounds.Store(&b)
+
+	return b
+}
+
+//operandのtransformが変わったら持っているboxを捨てて親にも伝える
+func (c *CSG) invalidateBounds() {
+	c.bounds.Store((*BoundingBox)(nil))
+	invalidateParentBounds(c.GetParent())
+}
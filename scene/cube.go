@@ -3,7 +3,6 @@ package scene
 import (
 	"math"
 	"rayGo/calc"
-	"rayGo/util"
 )
 
 type Cube struct {
@@ -40,22 +39,22 @@ func (c Cube) NormalAt(worldPoint calc.Tuple4, hit Intersection) (calc.Tuple4, e
 	return c.ShapeNormalAt(worldPoint, hit, c.calcLocalNormal)
 }
 
-func calcTminAndTmax(tminNumerator, tmaxNumerator, directionComponent float64) (float64, float64) {
+func calcTminAndTmax(tminNumerator, tmaxNumerator, directionComponent, tolerance float64) (float64, float64) {
 
 	//divisionByZero対策
-	if util.IsNearlyEqualZero(directionComponent) {
+	if isNearlyZeroWithin(directionComponent, tolerance) {
 		return tminNumerator * math.Inf(0), tmaxNumerator * math.Inf(0)
 	}
 
 	return tminNumerator / directionComponent, tmaxNumerator / directionComponent
 }
 
-func checkAxis(originComponent, directionComponent float64) (float64, float64) {
+func checkAxis(originComponent, directionComponent, tolerance float64) (float64, float64) {
 
 	tmin_numerator := (-1 - originComponent)
 	tmax_numerator := (1 - originComponent)
 
-	tmin, tmax := calcTminAndTmax(tmin_numerator, tmax_numerator, directionComponent)
+	tmin, tmax := calcTminAndTmax(tmin_numerator, tmax_numerator, directionComponent, tolerance)
 
 	if tmin > tmax {
 		tmin, tmax = tmax, tmin
@@ -65,9 +64,9 @@ func checkAxis(originComponent, directionComponent float64) (float64, float64) {
 }
 
 func (c Cube) calcLocalIntersect(r Ray) (Intersections, error) {
-	xtmin, xtmax := checkAxis(r.Origin[0], r.Direction[0])
-	ytmin, ytmax := checkAxis(r.Origin[1], r.Direction[1])
-	ztmin, ztmax := checkAxis(r.Origin[2], r.Direction[2])
+	xtmin, xtmax := checkAxis(r.Origin[0], r.Direction[0], r.tolerance())
+	ytmin, ytmax := checkAxis(r.Origin[1], r.Direction[1], r.tolerance())
+	ztmin, ztmax := checkAxis(r.Origin[2], r.Direction[2], r.tolerance())
 
 	tmin := math.Max(xtmin, math.Max(ytmin, ztmin))
 	tmax := math.Min(xtmax, math.Min(ytmax, ztmax))
@@ -105,3 +104,7 @@ func (c Cube) SetMaterial(m *Material) {
 func (c Cube) IsInclude(s Shape) bool {
 	return c == s
 }
+
+func (c Cube) Bounds() BoundingBox {
+	return NewBoundingBox(calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1))
+}
@@ -144,7 +144,7 @@ func (c Cyliner) checkCap(ray Ray, t float64) bool {
 func (c Cyliner) intersectCaps(ray Ray, sections []*Intersection) Intersections {
 
 	//cyn does not have caps or rayDirection.y is cloase to zero
-	if !c.Closed || util.IsNearlyEqualZero(ray.Direction[1]) {
+	if !c.Closed || ray.isNearlyZero(ray.Direction[1]) {
 		return AggregateIntersection(sections...)
 	}
 
@@ -164,7 +164,7 @@ func (c Cyliner) calcLocalIntersect(r Ray) (Intersections, error) {
 
 	//ray is parallel to y axis, because ray.Direction.X and ray.Direction.Z are nearlyEqualZero, r.Direction is composed almost Y component
 	//上記の状況でほぼY要素しかなかったとしてもcylinderの上面と下面のCapに接しているときがるのでintersectCapを確かめる
-	if util.IsNearlyEqualZero(_a) {
+	if r.isNearlyZero(_a) {
 		return c.intersectCaps(r, []*Intersection{}), nil
 	}
 
@@ -199,3 +199,7 @@ func (c Cyliner) SetMaterial(m *Material) {
 func (c Cyliner) IsInclude(s Shape) bool {
 	return c == s
 }
+
+func (c Cyliner) Bounds() BoundingBox {
+	return NewBoundingBox(calc.NewPoint(-1, c.Min, -1), calc.NewPoint(1, c.Max, 1))
+}
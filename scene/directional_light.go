@@ -0,0 +1,48 @@
+package scene
+
+import (
+	"rayGo/calc"
+	"rayGo/util"
+)
+
+//太陽のように無限に遠い光源、どこでも同じDirectionから平行に光が届く
+//Directionは光が進む向き(光源からsceneへの向き)
+type DirectionalLight struct {
+	Direction calc.Tuple4
+	Intensity Color
+}
+
+var _ LightSource = DirectionalLight{}
+
+func NewDirectionalLight(direction calc.Tuple4, intensity Color) DirectionalLight {
+	return DirectionalLight{
+		Direction: direction.Normalize(),
+		Intensity: intensity,
+	}
+}
+
+//光源までの距離が無限なので、光源の向きにあるものには全て遮られる
+func (l DirectionalLight) IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	in_shadow, err := w.IsShadowedAlong(calc.NegTuple(l.Direction), util.Inf, point, settings)
+	if err != nil {
+		return 0, err
+	}
+
+	if in_shadow {
+		return 0, nil
+	}
+
+	return 1, nil
+}
+
+func (l DirectionalLight) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
+	return lighting(l, m, position, eye_vec, normal_vec, intensity, shape)
+}
+
+func (l DirectionalLight) DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	return phongDirectLighting(m, materialColor, l.Intensity, calc.NegTuple(l.Direction), eye_vec, normal_vec, intensity)
+}
+
+func (l DirectionalLight) GetIntensity() Color {
+	return l.Intensity
+}
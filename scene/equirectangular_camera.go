@@ -0,0 +1,51 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//全方位(360°×180°)を写すcamera、横が経度で縦が緯度
+//canvasの中心が-zの向きで、VR用の画像やenvironment mapを作るのに使う
+//横縦比が2:1のときにpixelが正しい比率になる
+type EquirectangularCamera struct {
+	HSize float64
+	VSize float64
+	viewTransform
+}
+
+var _ Projector = EquirectangularCamera{}
+
+func NewEquirectangularCamera(hSize, vSize float64) EquirectangularCamera {
+	return EquirectangularCamera{
+		HSize:         hSize,
+		VSize:         vSize,
+		viewTransform: newViewTransform(),
+	}
+}
+
+func (c EquirectangularCamera) CanvasSize() (width, height int) {
+	return int(c.HSize), int(c.VSize)
+}
+
+func (c EquirectangularCamera) RayForPixel(px, py float64) (Ray, error) {
+	return c.RayForPixelSample(px, py, PixelCenter)
+}
+
+func (c EquirectangularCamera) RayForPixelSample(px, py float64, sample PixelSample) (Ray, error) {
+	u := (px + sample.X) / c.HSize
+	v := (py + sample.Y) / c.VSize
+
+	//経度は右に行くほど大きく、緯度は上に行くほど大きい
+	longitude := (u - 0.5) * 2 * math.Pi
+	latitude := (0.5 - v) * math.Pi
+
+	//camera空間の+xは左なので、右を向くとxが負になる
+	direction := calc.NewVector(
+		-math.Cos(latitude)*math.Sin(longitude),
+		math.Sin(latitude),
+		-math.Cos(latitude)*math.Cos(longitude),
+	)
+
+	return c.cameraRay(calc.NewPoint(0, 0, 0), direction)
+}
@@ -0,0 +1,55 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Equirectangular_Camera_Rays(t *testing.T) {
+	camera := NewEquirectangularCamera(400, 200)
+
+	w, h := camera.CanvasSize()
+	require.Equal(t, 400, w)
+	require.Equal(t, 200, h)
+
+	for _, target := range []struct {
+		title string
+		px    float64
+		py    float64
+		ans   calc.Tuple4
+	}{
+		{"center looks forward", 200, 100, calc.NewVector(0, 0, -1)},
+		{"quarter to the left", 100, 100, calc.NewVector(1, 0, 0)},
+		{"quarter to the right", 300, 100, calc.NewVector(-1, 0, 0)},
+		{"left edge looks backward", 0, 100, calc.NewVector(0, 0, 1)},
+		{"top edge looks up", 200, 0, calc.NewVector(0, 1, 0)},
+		{"bottom edge looks down", 200, 200, calc.NewVector(0, -1, 0)},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			ray, err := camera.RayForPixelSample(target.px, target.py, PixelSample{X: 0, Y: 0})
+			require.Nil(t, err)
+			require.True(t, calc.TupleCompare(target.ans, ray.Direction), ray.Direction)
+		})
+	}
+}
+
+func Test_Equirectangular_Camera_With_ViewTransform(t *testing.T) {
+	camera := NewEquirectangularCamera(40, 20)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	ray, err := camera.RayForPixelSample(20, 10, PixelSample{X: 0, Y: 0})
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0, 0, -5), ray.Origin))
+	require.True(t, calc.TupleCompare(calc.NewVector(0, 0, 1), ray.Direction))
+
+	//真ん中は球が見え、後ろ向きのpixelには何も写らない
+	canvas, err := DefaultWorld().Render(camera)
+	require.Nil(t, err)
+	require.NotEqual(t, Black, canvas.Pixels[10][20])
+	require.Equal(t, Black, canvas.Pixels[10][0])
+
+	require.True(t, math.Abs(ray.Direction.Magnitude()-1) < 0.00001)
+}
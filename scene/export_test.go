@@ -2,6 +2,7 @@ package scene
 
 import (
 	"rayGo/calc"
+	"sync/atomic"
 )
 
 func CreateIntersection(t float64, object Shape) Intersection {
@@ -42,3 +43,43 @@ func (tp TestPattern) PatternAtShape(world_point calc.Tuple4, shape Shape) (Colo
 
 	return tp.PatternAtShapeOnBase(world_point, shape, tp.PatternAt)
 }
+
+//Intersectが呼ばれた回数を数えるだけのShape
+type TestShape struct {
+	*BaseShape
+	IntersectCount *int32
+}
+
+var _ Shape = TestShape{}
+
+func NewTestShape() TestShape {
+	return TestShape{
+		NewBaseShape(),
+		new(int32),
+	}
+}
+
+func (ts TestShape) Intersect(r Ray) (Intersections, error) {
+	atomic.AddInt32(ts.IntersectCount, 1)
+	return Intersections{}, nil
+}
+
+func (ts TestShape) NormalAt(worldPoint calc.Tuple4, hit Intersection) (calc.Tuple4, error) {
+	return calc.NewVector(0, 1, 0), nil
+}
+
+func (ts TestShape) GetMaterial() *Material {
+	return ts.Material
+}
+
+func (ts TestShape) SetMaterial(m *Material) {
+	ts.Material = m
+}
+
+func (ts TestShape) IsInclude(s Shape) bool {
+	return ts == s
+}
+
+func (ts TestShape) Bounds() BoundingBox {
+	return NewBoundingBox(calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1))
+}
@@ -0,0 +1,54 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//等距離射影の魚眼camera、画像の中心からの距離がrayと-zの間の角度に比例する
+//FieldOfViewはcanvasの短い辺の端から端までの角度で、2πまで指定できる
+//短い辺に内接する円の外側(四隅)にはFieldOfViewより外側が写る
+type FisheyeCamera struct {
+	HSize       float64
+	VSize       float64
+	FieldOfView float64
+	viewTransform
+}
+
+var _ Projector = FisheyeCamera{}
+
+func NewFisheyeCamera(hSize, vSize, fieldOfView float64) FisheyeCamera {
+	return FisheyeCamera{
+		HSize:         hSize,
+		VSize:         vSize,
+		FieldOfView:   fieldOfView,
+		viewTransform: newViewTransform(),
+	}
+}
+
+func (c FisheyeCamera) CanvasSize() (width, height int) {
+	return int(c.HSize), int(c.VSize)
+}
+
+func (c FisheyeCamera) RayForPixel(px, py float64) (Ray, error) {
+	return c.RayForPixelSample(px, py, PixelCenter)
+}
+
+func (c FisheyeCamera) RayForPixelSample(px, py float64, sample PixelSample) (Ray, error) {
+	radius := math.Min(c.HSize, c.VSize) / 2
+
+	//短い辺の半分を1とした画像の中心からの位置、+xは左
+	x := (c.HSize/2 - (px + sample.X)) / radius
+	y := (c.VSize/2 - (py + sample.Y)) / radius
+
+	theta := math.Sqrt(x*x+y*y) * c.FieldOfView / 2
+	phi := math.Atan2(y, x)
+
+	direction := calc.NewVector(
+		math.Sin(theta)*math.Cos(phi),
+		math.Sin(theta)*math.Sin(phi),
+		-math.Cos(theta),
+	)
+
+	return c.cameraRay(calc.NewPoint(0, 0, 0), direction)
+}
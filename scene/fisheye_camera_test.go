@@ -0,0 +1,53 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Fisheye_Camera_Rays(t *testing.T) {
+	camera := NewFisheyeCamera(200, 100, math.Pi)
+
+	w, h := camera.CanvasSize()
+	require.Equal(t, 200, w)
+	require.Equal(t, 100, h)
+
+	for _, target := range []struct {
+		title  string
+		px     float64
+		py     float64
+		sample PixelSample
+		ans    calc.Tuple4
+	}{
+		{"center looks forward", 100, 50, PixelSample{X: 0, Y: 0}, calc.NewVector(0, 0, -1)},
+		//短い辺の端はFieldOfViewの半分(90°)
+		{"top edge looks up", 100, 0, PixelSample{X: 0, Y: 0}, calc.NewVector(0, 1, 0)},
+		{"bottom edge looks down", 100, 99, PixelSample{X: 0, Y: 1}, calc.NewVector(0, -1, 0)},
+		{"left looks left", 50, 50, PixelSample{X: 0, Y: 0}, calc.NewVector(1, 0, 0)},
+		{"halfway is 45 degrees", 125, 50, PixelSample{X: 0, Y: 0}, calc.NewVector(-math.Sqrt(2)/2, 0, -math.Sqrt(2)/2)},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			ray, err := camera.RayForPixelSample(target.px, target.py, target.sample)
+			require.Nil(t, err)
+			require.True(t, calc.TupleCompare(calc.NewPoint(0, 0, 0), ray.Origin))
+			require.True(t, calc.TupleCompare(target.ans, ray.Direction), ray.Direction)
+		})
+	}
+}
+
+func Test_Fisheye_Camera_With_ViewTransform(t *testing.T) {
+	camera := NewFisheyeCamera(11, 11, math.Pi/2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	ray, err := camera.RayForPixel(5, 5)
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0, 0, -5), ray.Origin))
+	require.True(t, calc.TupleCompare(calc.NewVector(0, 0, 1), ray.Direction))
+
+	canvas, err := DefaultWorld().Render(camera)
+	require.Nil(t, err)
+	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), canvas.Pixels[5][5]))
+}
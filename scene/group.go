@@ -2,11 +2,17 @@ package scene
 
 import (
 	"rayGo/calc"
+	"sync"
+	"sync/atomic"
 )
 
+//Childrenは直接書き換えずAddChildrenを使うこと(BVHが作り直されない)
 type Group struct {
 	*BaseShape
 	Children []Shape
+	//並列Render中に初めてIntersectされたときに作るのでlockしておく
+	hierarchyLock sync.Mutex
+	hierarchy     atomic.Value
 }
 
 var _ Shape = &Group{}
@@ -14,8 +20,8 @@ var _ Shape = &Group{}
 func NewGroup() *Group {
 
 	return &Group{
-		NewBaseShape(),
-		[]Shape{},
+		BaseShape: NewBaseShape(),
+		Children:  []Shape{},
 	}
 
 }
@@ -26,6 +32,42 @@ func (g *Group) AddChildren(ss ...Shape) {
 	for _, shape := range ss {
 		shape.SetParent(g)
 	}
+
+	g.invalidateBounds()
+}
+
+func (g *Group) loadHierarchy() *boundingHierarchy {
+	h, _ := g.hierarchy.Load().(*boundingHierarchy)
+	return h
+}
+
+//BVHはIntersectかBoundsで必要になったときにまとめて作る
+//OBJ parserのように一つずつAddChildrenしても毎回作り直さずに済む
+func (g *Group) boundingHierarchy() *boundingHierarchy {
+	if h := g.loadHierarchy(); h != nil {
+		return h
+	}
+
+	g.hierarchyLock.Lock()
+	defer g.hierarchyLock.Unlock()
+
+	if h := g.loadHierarchy(); h != nil {
+		return h
+	}
+
+	h := newBoundingHierarchy(g.Children)
+	g.hierarchy.Store(h)
+
+	return h
+}
+
+func (g *Group) invalidateBounds() {
+	g.hierarchy.Store((*boundingHierarchy)(nil))
+	invalidateParentBounds(g.GetParent())
+}
+
+func (g *Group) Bounds() BoundingBox {
+	return g.boundingHierarchy().bounds
 }
 
 func (g *Group) calcLocalNormal(localPoint calc.Tuple4, hit Intersection) calc.Tuple4 {
@@ -42,16 +84,10 @@ func (g *Group) calcLocalIntersect(r Ray) (Intersections, error) {
 		return Intersections{}, nil
 	}
 
-	var xs []*Intersection
-
-	for _, child := range g.Children {
-		section, err := child.Intersect(r)
-
-		if err != nil {
-			return Intersections{}, err
-		}
-
-		xs = append(xs, section.Intersections...)
+	//boxに当たらない子はIntersectしない
+	xs, err := g.boundingHierarchy().intersect(r)
+	if err != nil {
+		return Intersections{}, err
 	}
 
 	return AggregateIntersection(xs...), nil
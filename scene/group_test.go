@@ -117,3 +117,153 @@ func Test_Converting_Normal_From_Object_To_WorldSpace(t *testing.T) {
 	require.True(t, calc.TupleCompare(calc.NewVector(0.2857, 0.4286, -0.8571), p))
 
 }
+
+func Test_Group_Bounds_Contain_Transformed_Children(t *testing.T) {
+	s := NewSphere(1)
+	s.SetTransform(calc.NewTranslation(2, 5, -3).MulByMat4x4(calc.NewScale(2, 2, 2)))
+
+	c := NewCyliner(CynMin(-2), CynMax(2))
+	c.SetTransform(calc.NewTranslation(-4, -1, 4).MulByMat4x4(calc.NewScale(0.5, 1, 0.5)))
+
+	g := NewGroup()
+	g.AddChildren(s, c)
+
+	box := g.Bounds()
+	require.True(t, calc.TupleCompare(calc.NewPoint(-4.5, -3, -5), box.Min))
+	require.True(t, calc.TupleCompare(calc.NewPoint(4, 7, 4.5), box.Max))
+}
+
+func Test_Group_Bounds_Updated_When_Child_Moves(t *testing.T) {
+	s := NewSphere(1)
+	g := NewGroup()
+	g.AddChildren(s)
+
+	require.Equal(t, calc.NewPoint(1, 1, 1), g.Bounds().Max)
+
+	s.SetTransform(calc.NewTranslation(10, 0, 0))
+	require.Equal(t, calc.NewPoint(11, 1, 1), g.Bounds().Max)
+
+	//孫が動いたときも外側のgroupまで伝わる
+	outer := NewGroup()
+	outer.AddChildren(g)
+	require.Equal(t, calc.NewPoint(11, 1, 1), outer.Bounds().Max)
+
+	s.SetTransform(calc.NewTranslation(0, 20, 0))
+	require.Equal(t, calc.NewPoint(1, 21, 1), outer.Bounds().Max)
+}
+
+func Test_Group_Skips_Children_Whose_Bounds_Are_Missed(t *testing.T) {
+	g := NewGroup()
+
+	var children []TestShape
+	for i := 0; i < 16; i++ {
+		child := NewTestShape()
+		child.SetTransform(calc.NewTranslation(float64(i*3), 0, 0))
+		children = append(children, child)
+		g.AddChildren(child)
+	}
+
+	//x=0にあるchildだけにrayが当たる
+	ray := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+	_, err := g.Intersect(ray)
+	require.Nil(t, err)
+
+	total := int32(0)
+	for _, child := range children {
+		total += *child.IntersectCount
+	}
+
+	require.Equal(t, int32(1), *children[0].IntersectCount)
+	//同じleafに入っているchild以外はIntersectされない
+	require.True(t, total <= bvhLeafSize)
+}
+
+func Test_Group_BVH_Matches_Brute_Force(t *testing.T) {
+	g := NewGroup()
+	var children []Shape
+
+	for x := -3; x <= 3; x++ {
+		for y := -3; y <= 3; y++ {
+			s := NewSphere(1)
+			s.SetTransform(calc.NewTranslation(float64(x)*1.5, float64(y)*1.5, float64(x*y)*0.3).MulByMat4x4(calc.NewScale(0.5, 0.5, 0.5)))
+			children = append(children, s)
+		}
+	}
+
+	inner := NewGroup()
+	inner.SetTransform(calc.NewRotateZ(math.Pi / 5))
+	inner.AddChildren(NewCube(), NewPlane())
+	children = append(children, inner)
+
+	g.AddChildren(children...)
+
+	for i := 0; i < 50; i++ {
+		origin := calc.NewPoint(float64(i%7)-3, float64(i%5)-2, -10)
+		direction := calc.NewVector(float64(i%3)*0.1-0.1, float64(i%4)*0.05, 1).Normalize()
+		ray := NewRay(origin, direction)
+
+		xs, err := g.Intersect(ray)
+		require.Nil(t, err)
+
+		var brute []*Intersection
+		for _, child := range children {
+			section, err := child.Intersect(ray)
+			require.Nil(t, err)
+			brute = append(brute, section.Intersections...)
+		}
+		expected := AggregateIntersection(brute...)
+
+		require.Equal(t, expected.Count, xs.Count)
+		for j := range expected.Intersections {
+			require.Equal(t, expected.Intersections[j].Time, xs.Intersections[j].Time)
+		}
+	}
+}
+
+func Test_CSG_Skips_Operands_When_Ray_Misses_Bounds(t *testing.T) {
+	left := NewTestShape()
+	right := NewTestShape()
+	right.SetTransform(calc.NewTranslation(1, 0, 0))
+
+	csg, err := NewCSG(CSGUnion, left, right)
+	require.Nil(t, err)
+
+	_, err = csg.Intersect(NewRay(calc.NewPoint(0, 5, -5), calc.NewVector(0, 0, 1)))
+	require.Nil(t, err)
+	require.Equal(t, int32(0), *left.IntersectCount)
+	require.Equal(t, int32(0), *right.IntersectCount)
+
+	_, err = csg.Intersect(NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1)))
+	require.Nil(t, err)
+	require.Equal(t, int32(1), *left.IntersectCount)
+	require.Equal(t, int32(1), *right.IntersectCount)
+}
+
+func Test_CSG_Bounds_Cached_Until_Operand_Moves(t *testing.T) {
+	left := NewSphere(1)
+	right := NewSphere(1)
+
+	csg, err := NewCSG(CSGUnion, left, right)
+	require.Nil(t, err)
+	require.Nil(t, csg.loadBounds())
+
+	_, err = csg.Intersect(NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1)))
+	require.Nil(t, err)
+	require.NotNil(t, csg.loadBounds())
+	require.Equal(t, calc.NewPoint(1, 1, 1), csg.Bounds().Max)
+
+	right.SetTransform(calc.NewTranslation(10, 0, 0))
+	require.Nil(t, csg.loadBounds())
+	require.Equal(t, calc.NewPoint(11, 1, 1), csg.Bounds().Max)
+
+	//CSGの中のgroupの子が動いたときも捨てる
+	g := NewGroup()
+	s := NewSphere(1)
+	g.AddChildren(s)
+	outer, err := NewCSG(CSGDifference, g, NewSphere(1))
+	require.Nil(t, err)
+	require.Equal(t, calc.NewPoint(1, 1, 1), outer.Bounds().Max)
+
+	s.SetTransform(calc.NewTranslation(0, 20, 0))
+	require.Equal(t, calc.NewPoint(1, 21, 1), outer.Bounds().Max)
+}
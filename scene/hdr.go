@@ -0,0 +1,79 @@
+package scene
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+//Portable Float Map、linearなfloatのまま書き出すのでclampしない
+//PFMは下の行から上の行への順で、scaleが負ならlittle endian
+func (c *Canvas) WritePFM(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "PF\n%d %d\n-1.0\n", c.Width, c.Height)
+
+	row := make([]byte, c.Width*3*4)
+	for y := c.Height - 1; 0 <= y; y-- {
+		pos := 0
+		for _, pixel := range c.Pixels[y] {
+			for i := 0; i <= 2; i++ {
+				binary.LittleEndian.PutUint32(row[pos:], math.Float32bits(float32(pixel.GetByIndex(i))))
+				pos += 4
+			}
+		}
+
+		if _, err := buf.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
+
+//三つのchannelで共通の指数を持つRGBE、負の値は0にする
+func toRGBE(c Color) [4]byte {
+	r, g, b := math.Max(c.Red, 0), math.Max(c.Green, 0), math.Max(c.Blue, 0)
+
+	v := math.Max(r, math.Max(g, b))
+	if v < 1e-32 {
+		return [4]byte{0, 0, 0, 0}
+	}
+
+	//v = mantissa * 2^exp (0.5 <= mantissa < 1)
+	mantissa, exp := math.Frexp(v)
+	//指数が1byteに収まらないほど明るい色は一番明るいRGBEにする
+	if exp > 127 {
+		return [4]byte{255, 255, 255, 255}
+	}
+
+	scale := mantissa * 256 / v
+
+	return [4]byte{
+		byte(r * scale),
+		byte(g * scale),
+		byte(b * scale),
+		byte(exp + 128),
+	}
+}
+
+//Radiance RGBE(.hdr)、scanlineは圧縮せずにそのまま書き出す
+func (c *Canvas) WriteHDR(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", c.Height, c.Width)
+
+	row := make([]byte, c.Width*4)
+	for y := 0; y < c.Height; y++ {
+		for x, pixel := range c.Pixels[y] {
+			rgbe := toRGBE(pixel)
+			copy(row[x*4:], rgbe[:])
+		}
+
+		if _, err := buf.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
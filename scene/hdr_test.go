@@ -0,0 +1,93 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Write_PFM(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c.WritePixel(0, 0, NewColor(3.5, 0, -1))
+	c.WritePixel(1, 1, NewColor(0.25, 100, 0.5))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePFM(&buf))
+
+	header := "PF\n2 2\n-1.0\n"
+	require.Equal(t, header, buf.String()[:len(header)])
+
+	data := buf.Bytes()[len(header):]
+	require.Equal(t, 2*2*3*4, len(data))
+
+	values := make([]float32, 12)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	//下の行が先で、1を超える値も負の値もそのまま
+	require.Equal(t, []float32{
+		0, 0, 0, 0.25, 100, 0.5,
+		3.5, 0, -1, 0, 0, 0,
+	}, values)
+}
+
+func Test_RGBE(t *testing.T) {
+	for _, target := range []struct {
+		color Color
+		ans   [4]byte
+	}{
+		{Black, [4]byte{0, 0, 0, 0}},
+		{NewColor(1, 1, 1), [4]byte{128, 128, 128, 129}},
+		{NewColor(0.5, 0.25, 0), [4]byte{128, 64, 0, 128}},
+		{NewColor(10, 5, -3), [4]byte{160, 80, 0, 132}},
+	} {
+		require.Equal(t, target.ans, toRGBE(target.color))
+	}
+}
+
+func Test_Write_HDR(t *testing.T) {
+	c := NewCanvas(3, 2)
+	c.WritePixel(2, 1, NewColor(10, 5, 0))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WriteHDR(&buf))
+
+	header := "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 2 +X 3\n"
+	require.True(t, strings.HasPrefix(buf.String(), header))
+
+	data := buf.Bytes()[len(header):]
+	require.Equal(t, 3*2*4, len(data))
+	require.Equal(t, []byte{160, 80, 0, 132}, data[len(data)-4:])
+}
+
+func Test_Save_HDR_Formats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "canvas")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewCanvas(2, 1)
+	c.WritePixel(0, 0, NewColor(4, 2, 1))
+
+	for _, target := range []struct {
+		name   string
+		prefix string
+	}{
+		{"out.pfm", "PF\n"},
+		{"out.hdr", "#?RADIANCE\n"},
+	} {
+		path := filepath.Join(dir, target.name)
+		require.Nil(t, c.Save(path))
+
+		data, err := ioutil.ReadFile(path)
+		require.Nil(t, err)
+		require.True(t, strings.HasPrefix(string(data), target.prefix))
+	}
+}
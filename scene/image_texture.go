@@ -0,0 +1,133 @@
+package scene
+
+import "math"
+
+//texelの間をどう埋めるか
+type TextureFilter int
+
+const (
+	//一番近いtexelの色、拡大するとtexelが四角く見える
+	TextureNearest TextureFilter = iota
+	//周りの4つのtexelを線形に補間する
+	TextureBilinear
+)
+
+//0~1の外の(u, v)をどう扱うか
+type TextureAddress int
+
+const (
+	//同じ画像を繰り返す
+	TextureWrap TextureAddress = iota
+	//端のtexelを引き伸ばす
+	TextureClamp
+	//一枚おきに裏返して繰り返すので継ぎ目が目立たない
+	TextureMirror
+)
+
+type TextureOptions struct {
+	Filter  TextureFilter
+	Address TextureAddress
+}
+
+type TextureOption func(*TextureOptions)
+
+func TextureFiltering(filter TextureFilter) TextureOption {
+	return func(o *TextureOptions) {
+		o.Filter = filter
+	}
+}
+
+func TextureAddressing(address TextureAddress) TextureOption {
+	return func(o *TextureOptions) {
+		o.Address = address
+	}
+}
+
+func newTextureOptions(options ...TextureOption) *TextureOptions {
+	defaultOptions := &TextureOptions{
+		TextureBilinear,
+		TextureWrap,
+	}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
+
+	return defaultOptions
+}
+
+//画像を(u, v)で引くUVPattern、uは左から右、vは下から上
+//TextureMapPatternで貼ればどのshapeにも使える
+//画像はLoadCanvasで読む、PNGやJPEGはImageTransfer(SRGBTransfer{})でlinearに戻すとよい
+type ImageTexture struct {
+	Image   *Canvas
+	Filter  TextureFilter
+	Address TextureAddress
+}
+
+var _ UVPattern = ImageTexture{}
+
+func NewImageTexture(image *Canvas, options ...TextureOption) ImageTexture {
+	opts := newTextureOptions(options...)
+
+	return ImageTexture{
+		Image:   image,
+		Filter:  opts.Filter,
+		Address: opts.Address,
+	}
+}
+
+func (t ImageTexture) UVPatternAt(u, v float64) Color {
+	if t.Image == nil || t.Image.Width == 0 || t.Image.Height == 0 {
+		return Black
+	}
+
+	//画像の0行目が上なのでvを反転する
+	x := u * float64(t.Image.Width)
+	y := (1 - v) * float64(t.Image.Height)
+
+	if t.Filter == TextureNearest {
+		return t.texel(int(math.Floor(x)), int(math.Floor(y)))
+	}
+
+	//texelの中心の間を補間する
+	x -= 0.5
+	y -= 0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	top := t.texel(ix, iy).MulByScalar(1 - fx).Add(t.texel(ix+1, iy).MulByScalar(fx))
+	bottom := t.texel(ix, iy+1).MulByScalar(1 - fx).Add(t.texel(ix+1, iy+1).MulByScalar(fx))
+
+	return top.MulByScalar(1 - fy).Add(bottom.MulByScalar(fy))
+}
+
+func (t ImageTexture) texel(x, y int) Color {
+	return t.Image.Pixels[t.address(y, t.Image.Height)][t.address(x, t.Image.Width)]
+}
+
+//範囲外のindexをAddressに従って0~size-1に収める
+func (t ImageTexture) address(i, size int) int {
+	switch t.Address {
+	case TextureClamp:
+		if i < 0 {
+			return 0
+		}
+		if i >= size {
+			return size - 1
+		}
+
+		return i
+	case TextureMirror:
+		period := 2 * size
+		i = ((i % period) + period) % period
+		if i >= size {
+			return period - 1 - i
+		}
+
+		return i
+	default:
+		return ((i % size) + size) % size
+	}
+}
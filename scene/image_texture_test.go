@@ -0,0 +1,172 @@
+package scene
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//左上から赤、緑、青、白の2×2
+func testTextureImage() *Canvas {
+	c := NewCanvas(2, 2)
+	c.WritePixel(0, 0, Red)
+	c.WritePixel(1, 0, Green)
+	c.WritePixel(0, 1, Blue)
+	c.WritePixel(1, 1, White)
+
+	return c
+}
+
+func Test_Image_Texture_Nearest(t *testing.T) {
+	texture := NewImageTexture(testTextureImage(), TextureFiltering(TextureNearest))
+
+	for _, target := range []struct {
+		u   float64
+		v   float64
+		ans Color
+	}{
+		//vは下から上
+		{0.25, 0.75, Red},
+		{0.75, 0.75, Green},
+		{0.25, 0.25, Blue},
+		{0.75, 0.25, White},
+		{0, 0.99, Red},
+		{0.49, 0.51, Red},
+	} {
+		require.Equal(t, target.ans, texture.UVPatternAt(target.u, target.v), target)
+	}
+}
+
+func Test_Image_Texture_Bilinear(t *testing.T) {
+	texture := NewImageTexture(testTextureImage())
+	require.Equal(t, TextureBilinear, texture.Filter)
+
+	//texelの中心ではそのままの色
+	require.True(t, colorCompare(Red, texture.UVPatternAt(0.25, 0.75)))
+	require.True(t, colorCompare(White, texture.UVPatternAt(0.75, 0.25)))
+
+	//上の2つのtexelの間
+	require.True(t, colorCompare(NewColor(0.5, 0.5, 0), texture.UVPatternAt(0.5, 0.75)))
+
+	//真ん中は4つの平均
+	require.True(t, colorCompare(NewColor(0.5, 0.5, 0.5), texture.UVPatternAt(0.5, 0.5)))
+}
+
+func Test_Image_Texture_Addressing(t *testing.T) {
+	image := NewCanvas(4, 1)
+	for x := 0; x < 4; x++ {
+		image.WritePixel(x, 0, NewColor(float64(x), 0, 0))
+	}
+
+	for _, target := range []struct {
+		address TextureAddress
+		u       []float64
+		ans     []float64
+	}{
+		{TextureWrap, []float64{-0.125, 1.125, 2.375}, []float64{3, 0, 1}},
+		{TextureClamp, []float64{-0.125, 1.125, 2.375}, []float64{0, 3, 3}},
+		{TextureMirror, []float64{-0.125, 1.125, 1.875, 2.125}, []float64{0, 3, 0, 0}},
+	} {
+		texture := NewImageTexture(image, TextureFiltering(TextureNearest), TextureAddressing(target.address))
+		for i, u := range target.u {
+			require.Equal(t, target.ans[i], texture.UVPatternAt(u, 0.5).Red, target)
+		}
+	}
+
+	//bilinearでも端の外側はAddressに従う
+	wrap := NewImageTexture(image)
+	require.True(t, math.Abs(1.5-wrap.UVPatternAt(0, 0.5).Red) < 0.00001)
+
+	clamp := NewImageTexture(image, TextureAddressing(TextureClamp))
+	require.True(t, math.Abs(0-clamp.UVPatternAt(0, 0.5).Red) < 0.00001)
+}
+
+func Test_Image_Texture_Without_Image(t *testing.T) {
+	require.Equal(t, Black, ImageTexture{}.UVPatternAt(0.5, 0.5))
+}
+
+func Test_Image_Texture_On_Shapes(t *testing.T) {
+	pattern := NewTextureMapPattern(NewImageTexture(testTextureImage(), TextureFiltering(TextureNearest)), nil)
+
+	for _, target := range []struct {
+		shape Shape
+		point calc.Tuple4
+		ans   Color
+	}{
+		//球の-zの向きはu=0で赤道はv=0.5なので左下の青と左上の赤の境目
+		{NewSphere(1), calc.NewPoint(0, 0.1, -1), Red},
+		{NewSphere(1), calc.NewPoint(0, -0.1, -1), Blue},
+		{NewPlane(), calc.NewPoint(0.75, 0, 0.25), White},
+		{NewCube(), calc.NewPoint(0.5, 0.5, 1), Green},
+		{NewCyliner(), calc.NewPoint(0, 0.75, -1), Red},
+		{NewCone(), calc.NewPoint(0, 0.25, -0.25), Blue},
+	} {
+		c, err := pattern.PatternAtShape(target.point, target.shape)
+		require.Nil(t, err)
+		require.Equal(t, target.ans, c, target)
+	}
+}
+
+func Test_Load_Canvas_PNG_And_JPEG(t *testing.T) {
+	dir, err := ioutil.TempDir("", "texture")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewCanvas(3, 2)
+	c.WritePixel(0, 0, NewColor(1, 0, 0))
+	c.WritePixel(2, 1, NewColor(0, 0, 1))
+
+	pngPath := filepath.Join(dir, "in.png")
+	require.Nil(t, c.Save(pngPath))
+
+	read, err := LoadCanvas(pngPath)
+	require.Nil(t, err)
+	require.Equal(t, c.Pixels, read.Pixels)
+
+	//JPEGは非可逆なので大体の色を見る
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	jpegPath := filepath.Join(dir, "in.jpg")
+	fp, err := os.Create(jpegPath)
+	require.Nil(t, err)
+	require.Nil(t, jpeg.Encode(fp, img, &jpeg.Options{Quality: 100}))
+	require.Nil(t, fp.Close())
+
+	read, err = LoadCanvas(jpegPath)
+	require.Nil(t, err)
+	require.Equal(t, 16, read.Width)
+	require.True(t, math.Abs(read.Pixels[8][8].Red-200.0/255) < 0.02)
+	require.True(t, math.Abs(read.Pixels[8][8].Green-100.0/255) < 0.02)
+
+	//sRGBでdecodeするとlinearな値になる
+	linear, err := LoadCanvas(jpegPath, ImageTransfer(SRGBTransfer{}))
+	require.Nil(t, err)
+	require.True(t, math.Abs(linear.Pixels[8][8].Red-SRGBTransfer{}.Decode(200.0/255)) < 0.02)
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "broken.png"), []byte("not a png"), 0644))
+	_, err = LoadCanvas(filepath.Join(dir, "broken.png"))
+	require.IsType(t, ImageFormatError{}, err)
+}
+
+func Test_Canvas_From_Image_Unpremultiplies_Alpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, G: 0, B: 0, A: 128})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 0})
+
+	c := CanvasFromImage(img)
+	require.True(t, math.Abs(c.Pixels[0][0].Red-1) < 0.01)
+	require.Equal(t, Black, c.Pixels[0][1])
+}
@@ -6,66 +6,124 @@ import (
 )
 
 type Light struct {
-	Position  calc.Tuple4
-	Intensity Color
+	Position    calc.Tuple4
+	Intensity   Color
+	Attenuation Attenuation
 }
 
-func NewLight(p calc.Tuple4, color Color) Light {
+func NewLight(p calc.Tuple4, color Color, options ...LightOption) Light {
+	opts := newLightOptions(options...)
+
 	return Light{
-		Position:  p,
-		Intensity: color,
+		Position:    p,
+		Intensity:   color,
+		Attenuation: opts.Attenuation,
 	}
 }
 
-//shapeのmaterial,position
-//eyeVec(rayのnegate)
-//shapeのpositionに対してのnormalVec
-//light.Positionは光源の位置、shape.Positionは図形の位置
+//WorldにはLightやAreaLightをまとめて置けるようにする
+type LightSource interface {
+	//pointから光源がどれだけ見えているか、0なら完全に影で1なら全く遮られていない
+	IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error)
+	//この光源だけで照らしたときの色、ambientも含む
+	Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error)
+	//ambientを除いたdiffuseとspecular、materialColorはpatternを適用した色
+	//光源が複数あるときWorldはambientを一度だけ足してこれを光源ごとに足す
+	DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color
+	//ambientに使う光源の色
+	GetIntensity() Color
+}
 
-//Borrow from Phone Reflection Model
-//さすがにtuple絡みは関数じゃなくてメソッドを使ってChainさせた方が良さそうな感じ
+var _ LightSource = Light{}
 
-//light_dot_normalがepsilon(小数点第五位の1のずれ)を超えてずれてしまっている
-func (l *Light) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, inShadow bool, shape Shape) (Color, error) {
+func (l Light) IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	in_shadow, err := w.IsShadowed(l.Position, point, settings)
+	if err != nil {
+		return 0, err
+	}
+
+	if in_shadow {
+		return 0, nil
+	}
+
+	return 1, nil
+}
+
+//light_vecはpositionから光源への単位vector
+func diffuseAndSpecular(m *Material, effective_color Color, intensity Color, light_vec, eye_vec, normal_vec calc.Tuple4) (diffuse, specular Color) {
+	light_dot_normal := calc.DotTuple(light_vec, normal_vec)
+
+	//光源が面の裏側にある
+	if light_dot_normal < 0 {
+		return Black, Black
+	}
+
+	diffuse = effective_color.MulByScalar(m.Diffuse).MulByScalar(light_dot_normal)
+
+	reflect_vec := calc.Reflect(calc.NegTuple(light_vec), normal_vec)
+	refelect_dot_eye := calc.DotTuple(reflect_vec, eye_vec)
+
+	if refelect_dot_eye <= 0 {
+		return diffuse, Black
+	}
+
+	factor := math.Pow(refelect_dot_eye, m.Shininess)
+	specular = intensity.MulByScalar(m.Specular).MulByScalar(factor)
+
+	return diffuse, specular
+}
+
+//ambientは光源の向きによらずmaterialの色に光の色を掛けるだけ
+func ambientColor(m *Material, materialColor, lightIntensity Color) Color {
+	return materialColor.Mul(lightIntensity).MulByScalar(m.Ambient)
+}
 
+//一つの光源でのPhong、ambientとDirectLightingを足す
+func lighting(l LightSource, m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
 	materialColor, err := m.GetMaterialColor(position, shape)
 	if err != nil {
 		return Color{}, err
 	}
 
-	effective_color := materialColor.Mul(l.Intensity).ToTuple4()
+	ambient := ambientColor(m, materialColor, l.GetIntensity())
 
-	light_vec := calc.SubTuple(l.Position, position).Normalize()
-	ambient := TupletoColor(calc.MulTupleByScalar(m.Ambient, effective_color))
+	return ambient.Add(l.DirectLighting(m, materialColor, position, eye_vec, normal_vec, intensity)), nil
+}
 
+//光源の方向が一つに決まるLight,SpotLight,DirectionalLightで共通のPhong
+func phongDirectLighting(m *Material, materialColor, lightIntensity Color, light_vec, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
 	//shadowの中な場合lightの恩恵を受けられないのでdiffuseとspecularを無視
-	if inShadow {
-		return ambient, nil
+	if intensity == 0 {
+		return Black
 	}
 
-	light_dot_normal := calc.DotTuple(light_vec, normal_vec)
+	effective_color := materialColor.Mul(lightIntensity)
+	diffuse, specular := diffuseAndSpecular(m, effective_color, lightIntensity, light_vec, eye_vec, normal_vec)
 
-	var diffuse Color
-	var specular Color
+	return diffuse.MulByScalar(intensity).Add(specular.MulByScalar(intensity))
+}
 
-	if light_dot_normal < 0 {
-		diffuse = Black
-		specular = Black
+//shapeのmaterial,position
+//eyeVec(rayのnegate)
+//shapeのpositionに対してのnormalVec
+//light.Positionは光源の位置、shape.Positionは図形の位置
+//intensityはIntensityAtで求めた光源の見えている割合
 
-		return ambient.Add(diffuse).Add(specular), nil
-	}
+//Borrow from Phone Reflection Model
+//さすがにtuple絡みは関数じゃなくてメソッドを使ってChainさせた方が良さそうな感じ
 
-	diffuse = TupletoColor(calc.MulTupleByScalar(light_dot_normal, calc.MulTupleByScalar(m.Diffuse, effective_color)))
+//light_dot_normalがepsilon(小数点第五位の1のずれ)を超えてずれてしまっている
+func (l Light) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
+	return lighting(l, m, position, eye_vec, normal_vec, intensity, shape)
+}
 
-	reflect_vec := calc.Reflect(calc.NegTuple(light_vec), normal_vec)
-	refelect_dot_eye := calc.DotTuple(reflect_vec, eye_vec)
+func (l Light) DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	v := calc.SubTuple(l.Position, position)
+	attenuation := l.Attenuation.Factor(v.Magnitude())
 
-	if refelect_dot_eye <= 0 {
-		specular = Black
-	} else {
-		factor := math.Pow(refelect_dot_eye, m.Shininess)
-		specular = TupletoColor(calc.MulTupleByScalar(factor, calc.MulTupleByScalar(m.Specular, l.Intensity.ToTuple4())))
-	}
+	return phongDirectLighting(m, materialColor, l.Intensity, v.Normalize(), eye_vec, normal_vec, intensity*attenuation)
+}
 
-	return ambient.Add(diffuse).Add(specular), nil
+func (l Light) GetIntensity() Color {
+	return l.Intensity
 }
@@ -58,10 +58,10 @@ func TestLighting(t *testing.T) {
 	m := DefaultMaterial()
 	s.SetMaterial(m)
 	pos := calc.NewPoint(0, 0, 0)
-	in_shadow := false
+	intensity := 1.0
 	for _, target := range BuildLightTestSturct() {
 
-		res, err := target.light.Lighting(m, pos, target.eye_vec, target.normal_vec, in_shadow, s)
+		res, err := target.light.Lighting(m, pos, target.eye_vec, target.normal_vec, intensity, s)
 		require.Nil(t, err)
 
 		ret := colorCompare(target.ans, res)
@@ -76,14 +76,14 @@ func Test_Ignore_Diffuse_And_Specular_When_in_Shadow(t *testing.T) {
 	eye_vec := calc.NewVector(0, 0, -1)
 	normal_vec := calc.NewVector(0, 0, -1)
 	light := NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))
-	in_shadow := true
+	intensity := 0.0
 
 	s := NewSphere(1)
 	m := DefaultMaterial()
 	s.SetMaterial(m)
 
 	pos := calc.NewPoint(0, 0, 0)
-	res, err := light.Lighting(m, pos, eye_vec, normal_vec, in_shadow, s)
+	res, err := light.Lighting(m, pos, eye_vec, normal_vec, intensity, s)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0.1, 0.1, 0.1), res))
@@ -105,10 +105,10 @@ func Test_Lighting_with_Pattern(t *testing.T) {
 
 	light := NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))
 
-	c1, err := light.Lighting(m, calc.NewPoint(0.9, 0, 0), eye_vec, normal_vec, false, s)
+	c1, err := light.Lighting(m, calc.NewPoint(0.9, 0, 0), eye_vec, normal_vec, 1.0, s)
 	require.Nil(t, err)
 
-	c2, err := light.Lighting(m, calc.NewPoint(1.1, 0, 0), eye_vec, normal_vec, false, s)
+	c2, err := light.Lighting(m, calc.NewPoint(1.1, 0, 0), eye_vec, normal_vec, 1.0, s)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(White, c1))
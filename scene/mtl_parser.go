@@ -0,0 +1,230 @@
+package scene
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//mtlのnewmtl一つ分
+//illumはKsなど後に書かれる値も使うので、全部読んでからToMaterialでMaterialにする
+type MtlMaterial struct {
+	Name string
+	//Ka,Kd,Ks
+	AmbientColor  Color
+	DiffuseColor  Color
+	SpecularColor Color
+	//Ns
+	Shininess float64
+	//dは不透明度、Trは1-d
+	Dissolve float64
+	//Ni
+	OpticalDensity float64
+	Illum          int
+	//map_Kd、mtl fileのdirectoryからの相対pathは解決済み
+	DiffuseMap string
+
+	hasAmbient   bool
+	hasDiffuse   bool
+	hasSpecular  bool
+	hasShininess bool
+}
+
+//値の省略時はd 1,Ni 1,illum 2(highlightあり)として扱う
+func NewMtlMaterial(name string) *MtlMaterial {
+	return &MtlMaterial{
+		Name:           name,
+		Dissolve:       1,
+		OpticalDensity: 1,
+		Illum:          2,
+	}
+}
+
+func colorMean(c Color) float64 {
+	return (c.Red + c.Green + c.Blue) / 3
+}
+
+//MaterialはColorに対する係数でPhongを計算するので
+//Kdをそのまま色にしてDiffuseを1、KaとKsは平均を係数にする
+//書かれていない値はDefaultMaterialのまま
+func (mm *MtlMaterial) ToMaterial(diffuseMap UVPattern) *Material {
+	m := DefaultMaterial()
+
+	if mm.hasDiffuse {
+		m.Color = mm.DiffuseColor
+		m.Diffuse = 1
+	}
+	if mm.hasAmbient {
+		m.Ambient = colorMean(mm.AmbientColor)
+	}
+	if mm.hasSpecular {
+		m.Specular = colorMean(mm.SpecularColor)
+	}
+	if mm.hasShininess {
+		m.Shininess = mm.Shininess
+	}
+
+	m.Transparency = 1 - mm.Dissolve
+	m.RefractiveIndex = mm.OpticalDensity
+
+	switch mm.Illum {
+	//光源を無視してKdの色だけ
+	case 0:
+		m.Ambient = 1
+		m.Diffuse = 0
+		m.Specular = 0
+	//highlightなし
+	case 1:
+		m.Specular = 0
+	//ray traceの反射あり、強さはKs
+	case 3, 4, 5, 6, 7:
+		m.Reflective = colorMean(mm.SpecularColor)
+	}
+
+	if diffuseMap != nil {
+		//meshのvtがあればそれを、なければshapeに合わせたUVMappingを使う
+		m.SetPattern(NewTextureMapPattern(diffuseMap, nil))
+	}
+
+	return m
+}
+
+func parseMtlFloats(fields []objToken, min, max int) ([]float64, error) {
+	if len(fields)-1 < min || max < len(fields)-1 {
+		return nil, atStatement(NewParserError("invalid "+fields[0].text+" columns"), fields)
+	}
+
+	return retrieveComponentFromData(fields[1:])
+}
+
+//Ka 1 0.5 0のように三つの値、一つだけなら灰色とみなす
+//spectral,xyzの指定には対応しない
+func parseMtlColor(fields []objToken) (Color, error) {
+	data, err := parseMtlFloats(fields, 1, 3)
+	if err != nil {
+		return Color{}, err
+	}
+
+	switch len(data) {
+	case 1:
+		return NewColor(data[0], data[0], data[0]), nil
+	case 3:
+		return NewColor(data[0], data[1], data[2]), nil
+	default:
+		return Color{}, atStatement(NewParserError("invalid "+fields[0].text+" columns"), fields)
+	}
+}
+
+func parseMtlFloat(fields []objToken) (float64, error) {
+	data, err := parseMtlFloats(fields, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+func (mm *MtlMaterial) parseLine(fields []objToken, baseDir string) error {
+	var err error
+
+	switch fields[0].text {
+	case "Ka":
+		mm.AmbientColor, err = parseMtlColor(fields)
+		mm.hasAmbient = true
+	case "Kd":
+		mm.DiffuseColor, err = parseMtlColor(fields)
+		mm.hasDiffuse = true
+	case "Ks":
+		mm.SpecularColor, err = parseMtlColor(fields)
+		mm.hasSpecular = true
+	case "Ns":
+		mm.Shininess, err = parseMtlFloat(fields)
+		mm.hasShininess = true
+	case "d":
+		mm.Dissolve, err = parseMtlFloat(fields)
+	case "Tr":
+		var tr float64
+		tr, err = parseMtlFloat(fields)
+		mm.Dissolve = 1 - tr
+	case "Ni":
+		mm.OpticalDensity, err = parseMtlFloat(fields)
+	case "illum":
+		if len(fields) != 2 {
+			return atStatement(NewParserError("invalid illum columns"), fields)
+		}
+		mm.Illum, err = strconv.Atoi(fields[1].text)
+		if err != nil {
+			return atToken(NewParserError("invalid illum"), fields[1])
+		}
+	case "map_Kd":
+		//-s 1 1 1などのoptionは読み飛ばして最後をfile名とする
+		if len(fields) < 2 {
+			return atStatement(NewParserError("invalid map_Kd columns"), fields)
+		}
+		mm.DiffuseMap = resolvePath(baseDir, fields[len(fields)-1].text)
+	}
+
+	return err
+}
+
+//newmtlごとにまとめて返す、知らない文は無視する
+//エラーにはFileとしてpathを付ける
+func ParseMtl(path string) ([]*MtlMaterial, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseMtl(file, path)
+}
+
+func parseMtl(r io.Reader, path string) ([]*MtlMaterial, error) {
+	baseDir := filepath.Dir(path)
+
+	var materials []*MtlMaterial
+	var current *MtlMaterial
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		fields := tokenize(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0].text, "#") {
+			continue
+		}
+
+		var err error
+		switch {
+		case fields[0].text == "newmtl":
+			if len(fields) != 2 {
+				err = atStatement(NewParserError("invalid newmtl columns"), fields)
+				break
+			}
+
+			current = NewMtlMaterial(fields[1].text)
+			materials = append(materials, current)
+		//newmtlより前の文はどのmaterialにも属さないので無視する
+		case current != nil:
+			err = current.parseLine(fields, baseDir)
+		}
+
+		if err != nil {
+			parserError := atStatement(err, fields).(ParserError)
+			parserError.File = path
+			parserError.Line = lineNumber
+
+			return nil, parserError
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
@@ -0,0 +1,63 @@
+package scene
+
+import (
+	"rayGo/files"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_Mtl(t *testing.T) {
+	materials, err := ParseMtl(files.GetFilePath("test/materials.mtl"))
+	require.Nil(t, err)
+	require.Equal(t, 3, len(materials))
+
+	red := materials[0]
+	require.Equal(t, "red", red.Name)
+	require.Equal(t, NewColor(0.2, 0.2, 0.2), red.AmbientColor)
+	require.Equal(t, NewColor(1, 0, 0), red.DiffuseColor)
+	require.Equal(t, NewColor(0.5, 0.5, 0.5), red.SpecularColor)
+	require.Equal(t, 50.0, red.Shininess)
+	require.Equal(t, 0.75, red.Dissolve)
+	require.Equal(t, 1.5, red.OpticalDensity)
+	require.Equal(t, 3, red.Illum)
+
+	require.Equal(t, "flat", materials[1].Name)
+	require.Equal(t, 0.75, materials[1].Dissolve)
+
+	require.Equal(t, files.GetFilePath("test/texture.ppm"), materials[2].DiffuseMap)
+}
+
+func Test_Mtl_Material_To_Material(t *testing.T) {
+	materials, err := ParseMtl(files.GetFilePath("test/materials.mtl"))
+	require.Nil(t, err)
+
+	red := materials[0].ToMaterial(nil)
+	require.Equal(t, NewColor(1, 0, 0), red.Color)
+	require.InDelta(t, 0.2, red.Ambient, 1e-9)
+	require.Equal(t, 1.0, red.Diffuse)
+	require.InDelta(t, 0.5, red.Specular, 1e-9)
+	require.Equal(t, 50.0, red.Shininess)
+	require.Equal(t, 0.25, red.Transparency)
+	require.Equal(t, 1.5, red.RefractiveIndex)
+	require.InDelta(t, 0.5, red.Reflective, 1e-9)
+	require.Nil(t, red.Pattern)
+
+	flat := materials[1].ToMaterial(nil)
+	require.Equal(t, NewColor(0, 1, 0), flat.Color)
+	require.Equal(t, 1.0, flat.Ambient)
+	require.Equal(t, 0.0, flat.Diffuse)
+	require.Equal(t, 0.0, flat.Specular)
+	require.Equal(t, 0.25, flat.Transparency)
+
+	texture := NewUVCheckers(2, 2, White, Black)
+	textured := materials[2].ToMaterial(texture)
+	require.Equal(t, 0.0, textured.Specular)
+	require.Equal(t, DefaultMaterial().Ambient, textured.Ambient)
+	require.Equal(t, NewTextureMapPattern(texture, nil).UVPattern, textured.Pattern.(TextureMapPattern).UVPattern)
+}
+
+func Test_Parse_Mtl_Error(t *testing.T) {
+	_, err := ParseMtl(files.GetFilePath("test/badColumns.mtl"))
+	require.Equal(t, files.GetFilePath("test/badColumns.mtl")+":2:1: invalid Kd columns: \"Kd 1 0\"", err.Error())
+}
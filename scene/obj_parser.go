@@ -2,25 +2,101 @@ package scene
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"rayGo/calc"
 	"rayGo/files"
 	"strconv"
 	"strings"
 )
 
+//Line,Column,Textはエラーになった行と語、ParseLineで埋める
+//mtlのようにobjとは別のfileのエラーのときはFileも入る
 type ParserError struct {
-	message string
+	File    string
+	Line    int
+	Column  int
+	Text    string
+	Message string
 }
 
 func (p ParserError) Error() string {
-	return p.message
+	if p.Line == 0 {
+		return p.Message
+	}
+
+	position := fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+	if p.File != "" {
+		position = fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%s: %s: %q", position, p.Message, p.Text)
 }
 
 func NewParserError(message string) ParserError {
 	return ParserError{
-		message: message,
+		Message: message,
+	}
+}
+
+//行の中の空白かtabで区切られた一語、Columnは1-indexed
+type objToken struct {
+	text   string
+	column int
+}
+
+//空白やtabが続いても空の語は作らない
+func tokenize(line string) []objToken {
+	var tokens []objToken
+
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != '\r' {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+
+		if start != -1 {
+			tokens = append(tokens, objToken{line[start:i], start + 1})
+			start = -1
+		}
+	}
+
+	return tokens
+}
+
+//位置の決まっていないParserErrorならtokenの位置を付ける
+func atToken(err error, token objToken) error {
+	parserError, ok := err.(ParserError)
+	if !ok {
+		parserError = NewParserError(err.Error())
+	}
+
+	if parserError.Column != 0 || parserError.File != "" {
+		return parserError
+	}
+
+	parserError.Column = token.column
+	parserError.Text = token.text
+
+	return parserError
+}
+
+//語の数が合わないときなど、文全体を示すエラー
+func atStatement(err error, tokens []objToken) error {
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.text
 	}
+
+	return atToken(err, objToken{
+		strings.Join(texts, " "),
+		tokens[0].column,
+	})
 }
 
 type ParserGroup struct {
@@ -51,57 +127,60 @@ type Parser struct {
 	ParserGroups []*ParserGroup
 	Vertices     []calc.Tuple4
 	Normals      []calc.Tuple4
+	TexCoords    []TexCoord
+	//mtllibで読んだmaterial、usemtlの名前で引く
+	Materials map[string]*Material
+	//mtllibの相対pathはここから解決する、ParseObjではobj fileのdirectory
+	BaseDir string
+	//直前のusemtl、nilならfaceのmaterialはDefaultMaterialのまま
+	material *Material
+	//map_Kdの画像はmaterialをまたいで使い回す
+	textures map[string]UVPattern
+	//ParseLineで読んだ行数、ParserErrorのLineになる
+	lineNumber int
 }
 
 func NewParser() *Parser {
-	return &Parser{}
-}
-
-func getLines(fileName string) ([]string, error) {
-	path := files.GetFilePath(fileName)
-	file, err := os.Open(path)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer file.Close()
-
-	lines := []string{}
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	return &Parser{
+		Materials: map[string]*Material{},
+		textures:  map[string]UVPattern{},
 	}
+}
 
-	if err = scanner.Err(); err != nil {
-		return nil, err
+//絶対pathはそのまま、相対pathはbaseDirから
+func resolvePath(baseDir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
 	}
 
-	return lines, nil
-
+	return filepath.Join(baseDir, name)
 }
 
 type FaceComponent struct {
 	VertexNum       int
 	VertexNormalNum int
+	TexCoordNum     int
+	//vtの番号が書かれていたか、0番を書いたときもTexCoordNumは-1になるので別に持つ
+	HasTexCoord bool
 }
 
 func NewFaceComponent() FaceComponent {
 	return FaceComponent{
 		-1,
 		-1,
+		-1,
+		false,
 	}
 }
 
-func retrieveComponentFromData(data []string) ([]float64, error) {
+func retrieveComponentFromData(data []objToken) ([]float64, error) {
 
 	floatData := make([]float64, len(data))
 
 	for i := 0; i < len(data); i++ {
-		fp, err := strconv.ParseFloat(data[i], 64)
+		fp, err := strconv.ParseFloat(data[i].text, 64)
 		if err != nil {
-			return []float64{}, err
+			return []float64{}, atToken(NewParserError("invalid number"), data[i])
 		}
 
 		floatData[i] = fp
@@ -113,6 +192,7 @@ func retrieveComponentFromData(data []string) ([]float64, error) {
 
 // num or
 // num//num or
+// num/num or
 // num/num/num
 func parseFaceComponent(data string) (FaceComponent, error) {
 
@@ -129,8 +209,18 @@ func parseFaceComponent(data string) (FaceComponent, error) {
 	return parseNumOnly(data, faceComponent)
 }
 
-func parseNumOnly(data string, faceComponent FaceComponent) (FaceComponent, error) {
+//範囲外の番号はVertexDataにするときに確かめる
+func parseFaceIndex(data string) (int, error) {
 	num, err := strconv.Atoi(data)
+	if err != nil {
+		return 0, NewParserError("invalid face index")
+	}
+
+	return num, nil
+}
+
+func parseNumOnly(data string, faceComponent FaceComponent) (FaceComponent, error) {
+	num, err := parseFaceIndex(data)
 	if err != nil {
 		return faceComponent, err
 	}
@@ -147,11 +237,11 @@ func parseDoubleSlash(data string, faceComponent FaceComponent) (FaceComponent,
 		return faceComponent, NewParserError("invalid face double slash columns")
 	}
 
-	vertexNum, err := strconv.Atoi(doubleSlashComponent[0])
+	vertexNum, err := parseFaceIndex(doubleSlashComponent[0])
 	if err != nil {
 		return faceComponent, err
 	}
-	vertexNormalNum, err := strconv.Atoi(doubleSlashComponent[1])
+	vertexNormalNum, err := parseFaceIndex(doubleSlashComponent[1])
 	if err != nil {
 		return faceComponent, err
 	}
@@ -163,41 +253,50 @@ func parseDoubleSlash(data string, faceComponent FaceComponent) (FaceComponent,
 
 }
 
+//v/vtとv/vt/vnの二通り
 func parseSingleSlash(data string, faceComponent FaceComponent) (FaceComponent, error) {
-	// //区切り
+	// /区切り
 	singleSlashComponent := strings.Split(data, "/")
-	if len(singleSlashComponent) != 3 {
+	if len(singleSlashComponent) != 2 && len(singleSlashComponent) != 3 {
 		return faceComponent, NewParserError("invalid face single slash columns")
 	}
 
-	vertexNum, err := strconv.Atoi(singleSlashComponent[0])
+	vertexNum, err := parseFaceIndex(singleSlashComponent[0])
 	if err != nil {
 		return faceComponent, err
 	}
-	vertexNormalNum, err := strconv.Atoi(singleSlashComponent[2])
+	texCoordNum, err := parseFaceIndex(singleSlashComponent[1])
 	if err != nil {
 		return faceComponent, err
 	}
 
 	faceComponent.VertexNum = change0indexed(vertexNum)
+	faceComponent.TexCoordNum = change0indexed(texCoordNum)
+	faceComponent.HasTexCoord = true
+
+	if len(singleSlashComponent) == 2 {
+		return faceComponent, nil
+	}
+
+	vertexNormalNum, err := parseFaceIndex(singleSlashComponent[2])
+	if err != nil {
+		return faceComponent, err
+	}
+
 	faceComponent.VertexNormalNum = change0indexed(vertexNormalNum)
 
 	return faceComponent, nil
 
 }
 
-func retrieveFaceComponentFromData(data []string) ([]FaceComponent, error) {
+func retrieveFaceComponentFromData(data []objToken) ([]FaceComponent, error) {
 
 	faceData := make([]FaceComponent, len(data))
 
 	for i := 0; i < len(data); i++ {
-
-		if data[i] == "" {
-			continue
-		}
-		faceComponent, err := parseFaceComponent(data[i])
+		faceComponent, err := parseFaceComponent(data[i].text)
 		if err != nil {
-			return nil, err
+			return nil, atToken(err, data[i])
 		}
 		faceData[i] = faceComponent
 	}
@@ -206,25 +305,55 @@ func retrieveFaceComponentFromData(data []string) ([]FaceComponent, error) {
 
 }
 
+//絶対pathはそのまま、相対pathはfiles packageのdirectoryから探す
 func ParseObj(fileName string) (*Parser, error) {
+	path := fileName
+	if !filepath.IsAbs(path) {
+		path = files.GetFilePath(fileName)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return &Parser{}, err
+	}
+	defer file.Close()
 
 	parser := NewParser()
+	parser.BaseDir = filepath.Dir(path)
 
-	lines, err := getLines(fileName)
-	if err != nil {
+	if err := parser.Parse(file); err != nil {
 		return &Parser{}, err
 	}
 
-	for _, line := range lines {
-		err := parser.ParseLine(line)
-		if err != nil {
-			return &Parser{}, err
-		}
+	return parser, nil
+}
+
+//mtllibはcurrent directoryから探すので、別の場所にあるときはParserのBaseDirを設定してParseを使う
+func ParseObjReader(r io.Reader) (*Parser, error) {
+	parser := NewParser()
+
+	if err := parser.Parse(r); err != nil {
+		return &Parser{}, err
 	}
 
 	return parser, nil
 }
 
+//一行ずつ読んでParseLineに渡すので、全体をメモリに載せない
+func (p *Parser) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	//大きなmeshのfは一行が長くなることがある
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if err := p.ParseLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
 func createVertice(data []float64) (calc.Tuple4, error) {
 	if len(data) != 3 {
 		return calc.Tuple4{}, NewParserError("invalid vertice columns")
@@ -233,13 +362,9 @@ func createVertice(data []float64) (calc.Tuple4, error) {
 	return calc.NewPoint(data[0], data[1], data[2]), nil
 }
 
-func (p *Parser) parseVertice(line string) error {
-	//空白区切り
-
-	verticeComponent := strings.Split(line, " ")
-
+func (p *Parser) parseVertice(verticeComponent []objToken) error {
 	if len(verticeComponent) != 4 {
-		return NewParserError("invalid vertice columns")
+		return atStatement(NewParserError("invalid vertice columns"), verticeComponent)
 	}
 
 	verticeData, err := retrieveComponentFromData(verticeComponent[1:])
@@ -265,22 +390,28 @@ func (p *Parser) isValidVertexNormal(num int) bool {
 	return 0 <= num && num <= len(p.Normals)-1
 }
 
+func (p *Parser) isValidTexCoord(num int) bool {
+	return 0 <= num && num <= len(p.TexCoords)-1
+}
+
 //入力は1-indexedで受け付けているので
 func change0indexed(num int) int {
 	return num - 1
 }
 
 //ParseFaceで事前に得られたVeriticesから実際にtriangleを作っていく
-func (p *Parser) parseFace(line string) error {
-	//空白区切り
-	faceComponent := strings.Split(line, " ")
+func (p *Parser) parseFace(faceComponent []objToken) error {
+	//三角形にならない
+	if len(faceComponent) < 4 {
+		return atStatement(NewParserError("invalid face columns"), faceComponent)
+	}
 
 	faceData, err := retrieveFaceComponentFromData(faceComponent[1:])
 	if err != nil {
 		return err
 	}
 
-	return p.createObject(faceData)
+	return p.createObject(faceData, faceComponent[1:])
 
 }
 
@@ -304,12 +435,31 @@ func convertToPoint(vertexData VertexData) []calc.Tuple4 {
 	return points
 }
 
+func convertToTexCoord(vertexData VertexData) []TexCoord {
+	texCoords := make([]TexCoord, len(vertexData.data))
+
+	for i, d := range vertexData.data {
+		texCoords[i] = d.texCoord
+	}
+
+	return texCoords
+}
+
 func fanTriangulation(vertexData VertexData) []Shape {
 	var triangles []Shape
 
 	points := convertToPoint(vertexData)
+	texCoords := convertToTexCoord(vertexData)
 
 	for i := 1; i < len(points)-1; i++ {
+		if vertexData.isUseTexCoord {
+			triangles = append(triangles, NewTexturedTriangle(
+				points[0], points[i], points[i+1],
+				texCoords[0], texCoords[i], texCoords[i+1],
+			))
+			continue
+		}
+
 		triangles = append(triangles, NewTriangle(points[0], points[i], points[i+1]))
 	}
 
@@ -331,6 +481,16 @@ func fanTriangulation(vertexData VertexData) []Shape {
 func createSmoothTriangles(vertexData VertexData) []Shape {
 	one, two, three := vertexData.data[0], vertexData.data[1], vertexData.data[2]
 
+	if vertexData.isUseTexCoord {
+		return []Shape{
+			NewTexturedSmoothTriangle(
+				one.vertex, two.vertex, three.vertex,
+				one.vertexNormal, two.vertexNormal, three.vertexNormal,
+				one.texCoord, two.texCoord, three.texCoord,
+			),
+		}
+	}
+
 	return []Shape{
 		NewSmoothTriangle(
 			one.vertex, two.vertex, three.vertex,
@@ -367,14 +527,17 @@ func (p *Parser) latestGroup() *ParserGroup {
 type VertexDatum struct {
 	vertex       calc.Tuple4
 	vertexNormal calc.Tuple4
+	texCoord     TexCoord
 }
 
 type VertexData struct {
 	data              []VertexDatum
 	isUseVertexNormal bool
+	isUseTexCoord     bool
 }
 
-func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent) (VertexData, error) {
+//faceTokensはfaceDataと同じ順のtokenで、番号が不正なときのエラーの位置に使う
+func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent, faceTokens []objToken) (VertexData, error) {
 
 	setVertex := func(vertexs *VertexDatum, face FaceComponent) error {
 		if !p.isValidVertex(face.VertexNum) {
@@ -391,7 +554,7 @@ func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent) (V
 			return false, nil
 		}
 
-		if !p.isValidVertexNormal(face.VertexNum) {
+		if !p.isValidVertexNormal(face.VertexNormalNum) {
 			return true, NewParserError("invalid face num,please make sure It is valid vertex Normal")
 		}
 
@@ -400,9 +563,27 @@ func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent) (V
 		return true, nil
 	}
 
+	//vtが一つもないfileではf 1/0/3のような番号も受け付けて無視する
+	setTexCoord := func(vertexs *VertexDatum, face FaceComponent) (bool, error) {
+
+		if !face.HasTexCoord || len(p.TexCoords) == 0 {
+			return false, nil
+		}
+
+		if !p.isValidTexCoord(face.TexCoordNum) {
+			return true, NewParserError("invalid face num,please make sure It is valid texture coordinate")
+		}
+
+		vertexs.texCoord = p.TexCoords[face.TexCoordNum]
+
+		return true, nil
+	}
+
 	data := make([]VertexDatum, len(faceData))
 
 	isUseVertexNormal := true
+	//一つの頂点でもvtがなければtexture座標は使わない
+	isUseTexCoord := true
 
 	for i, eachFace := range faceData {
 
@@ -410,16 +591,23 @@ func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent) (V
 
 		err := setVertex(vertexs, eachFace)
 		if err != nil {
-			return VertexData{}, err
+			return VertexData{}, atToken(err, faceTokens[i])
 		}
 
 		useVertexNormal, err := setVertexNormal(vertexs, eachFace)
 		if err != nil {
-			return VertexData{}, err
+			return VertexData{}, atToken(err, faceTokens[i])
 		}
 
 		isUseVertexNormal = useVertexNormal
 
+		useTexCoord, err := setTexCoord(vertexs, eachFace)
+		if err != nil {
+			return VertexData{}, atToken(err, faceTokens[i])
+		}
+
+		isUseTexCoord = isUseTexCoord && useTexCoord
+
 		data[i] = *vertexs
 
 	}
@@ -427,10 +615,11 @@ func (p *Parser) convertDataToVertexAndVertexNormal(faceData []FaceComponent) (V
 	return VertexData{
 		data:              data,
 		isUseVertexNormal: isUseVertexNormal,
+		isUseTexCoord:     isUseTexCoord,
 	}, nil
 }
 
-func (p *Parser) createObject(faceData []FaceComponent) error {
+func (p *Parser) createObject(faceData []FaceComponent, faceTokens []objToken) error {
 
 	//g FirstGroup
 	//f 1 2 3
@@ -443,28 +632,28 @@ func (p *Parser) createObject(faceData []FaceComponent) error {
 		p.createDefaultGroup()
 	}
 
-	vertexData, err := p.convertDataToVertexAndVertexNormal(faceData)
+	vertexData, err := p.convertDataToVertexAndVertexNormal(faceData, faceTokens)
 
 	if err != nil {
 		return err
 	}
 
 	for _, tri := range p.createTriangles(vertexData) {
+		if p.material != nil {
+			tri.SetMaterial(p.material)
+		}
 		p.latestGroup().AddChildren(tri)
 	}
 
 	return nil
 }
 
-func (p *Parser) parseGroup(line string) error {
-	//空白区切り
-	groupComponent := strings.Split(line, " ")
-
+func (p *Parser) parseGroup(groupComponent []objToken) error {
 	if len(groupComponent) != 2 {
-		return NewParserError("invalid group columns")
+		return atStatement(NewParserError("invalid group columns"), groupComponent)
 	}
 
-	p.ParserGroups = append(p.ParserGroups, NewParserGroup(groupComponent[1]))
+	p.ParserGroups = append(p.ParserGroups, NewParserGroup(groupComponent[1].text))
 
 	return nil
 }
@@ -487,12 +676,9 @@ func createVerticeNormal(data []float64) (calc.Tuple4, error) {
 	return calc.NewVector(data[0], data[1], data[2]), nil
 }
 
-func (p *Parser) ParseVertexNormal(line string) error {
-	//空白区切り
-	vnComponent := strings.Split(line, " ")
-
+func (p *Parser) parseVertexNormal(vnComponent []objToken) error {
 	if len(vnComponent) != 4 {
-		return NewParserError("invalid vn columns")
+		return atStatement(NewParserError("invalid vn columns"), vnComponent)
 	}
 
 	vnDara, err := retrieveComponentFromData(vnComponent[1:])
@@ -510,38 +696,132 @@ func (p *Parser) ParseVertexNormal(line string) error {
 	return nil
 }
 
-//v~の奴を処理
-func (p *Parser) ParseVxxx(line string) error {
-	nextChar := line[1]
+//画像は色なのでsRGBとしてlinearに戻して読む
+func (p *Parser) loadTexture(path string) (UVPattern, error) {
+	if texture, ok := p.textures[path]; ok {
+		return texture, nil
+	}
 
-	switch nextChar {
-	//vn
-	case 'n':
-		return p.ParseVertexNormal(line)
-	default:
-		return p.parseVertice(line)
+	canvas, err := LoadCanvas(path, ImageTransfer(SRGBTransfer{}))
+	if err != nil {
+		return nil, err
 	}
+
+	texture := NewImageTexture(canvas)
+	p.textures[path] = texture
+
+	return texture, nil
 }
 
+//mtllib a.mtl b.mtlのように複数書ける、同じ名前のmaterialは後のfileで上書きする
+func (p *Parser) parseMtllib(mtllibComponent []objToken) error {
+	if len(mtllibComponent) < 2 {
+		return atStatement(NewParserError("invalid mtllib columns"), mtllibComponent)
+	}
+
+	for _, name := range mtllibComponent[1:] {
+		mtlMaterials, err := ParseMtl(resolvePath(p.BaseDir, name.text))
+		if err != nil {
+			return atToken(err, name)
+		}
+
+		for _, mtlMaterial := range mtlMaterials {
+			var diffuseMap UVPattern
+			if mtlMaterial.DiffuseMap != "" {
+				diffuseMap, err = p.loadTexture(mtlMaterial.DiffuseMap)
+				if err != nil {
+					return atToken(err, name)
+				}
+			}
+
+			p.Materials[mtlMaterial.Name] = mtlMaterial.ToMaterial(diffuseMap)
+		}
+	}
+
+	return nil
+}
+
+//以降のfaceにmaterialを付ける、mtllibより後に書く必要がある
+func (p *Parser) parseUsemtl(usemtlComponent []objToken) error {
+	if len(usemtlComponent) != 2 {
+		return atStatement(NewParserError("invalid usemtl columns"), usemtlComponent)
+	}
+
+	material, ok := p.Materials[usemtlComponent[1].text]
+	if !ok {
+		return atToken(NewParserError("unknown material"), usemtlComponent[1])
+	}
+
+	p.material = material
+
+	return nil
+}
+
+//vt u [v [w]]、v,wは省略すると0、wは使わない
+func (p *Parser) parseTexCoord(vtComponent []objToken) error {
+	if len(vtComponent) < 2 || 4 < len(vtComponent) {
+		return atStatement(NewParserError("invalid vt columns"), vtComponent)
+	}
+
+	vtData, err := retrieveComponentFromData(vtComponent[1:])
+	if err != nil {
+		return err
+	}
+
+	texCoord := NewTexCoord(vtData[0], 0)
+	if len(vtData) >= 2 {
+		texCoord.V = vtData[1]
+	}
+
+	p.TexCoords = append(p.TexCoords, texCoord)
+
+	return nil
+}
+
+//先頭の語で文を見分ける、知らない文とcommentは無視する
+//エラーには行番号と位置を付けて返す
 func (p *Parser) ParseLine(line string) error {
+	p.lineNumber++
 
-	//改行オンリーの行は飛ばす
-	if len(line) == 0 {
+	tokens := tokenize(line)
+
+	//空行は飛ばす
+	if len(tokens) == 0 {
 		return nil
 	}
 
-	startChar := line[0]
-
-	switch startChar {
-	case 'v':
-		return p.ParseVxxx(line)
-	case 'f':
-		return p.parseFace(line)
-	case 'g':
-		return p.parseGroup(line)
-	default:
+	err := p.parseStatement(tokens)
+	if err == nil {
 		return nil
+	}
 
+	parserError, ok := atStatement(err, tokens).(ParserError)
+	if ok && parserError.File == "" {
+		parserError.Line = p.lineNumber
 	}
 
+	return parserError
+}
+
+func (p *Parser) parseStatement(tokens []objToken) error {
+	switch tokens[0].text {
+	case "v":
+		return p.parseVertice(tokens)
+	//vn
+	case "vn":
+		return p.parseVertexNormal(tokens)
+	//vt
+	case "vt":
+		return p.parseTexCoord(tokens)
+	case "f":
+		return p.parseFace(tokens)
+	case "g":
+		return p.parseGroup(tokens)
+	case "mtllib":
+		return p.parseMtllib(tokens)
+	case "usemtl":
+		return p.parseUsemtl(tokens)
+	default:
+		return nil
+	}
 }
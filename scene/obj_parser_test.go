@@ -2,6 +2,8 @@ package scene
 
 import (
 	"rayGo/calc"
+	"rayGo/files"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,13 +33,13 @@ func Test_Parse_Verice(t *testing.T) {
 func Test_Parse_Invalid_Verice_Columns_Error(t *testing.T) {
 	_, err := ParseObj("test/test3.txt")
 
-	require.Equal(t, "invalid vertice columns", err.Error())
+	require.Equal(t, "line 4, column 1: invalid vertice columns: \"v 1 1 0 0\"", err.Error())
 }
 
 func Test_Parse_Not_Float_Verice_Error(t *testing.T) {
 	_, err := ParseObj("test/test4.txt")
 
-	require.Equal(t, "strconv.ParseFloat: parsing \"aaaaa\": invalid syntax", err.Error())
+	require.Equal(t, "line 1, column 8: invalid number: \"aaaaa\"", err.Error())
 }
 
 func Test_Parse_Triangle_Face(t *testing.T) {
@@ -66,7 +68,7 @@ func Test_Parse_Triangle_Face(t *testing.T) {
 
 func Test_Parse_Invalid_Face_Error(t *testing.T) {
 	_, err := ParseObj("test/test6.txt")
-	require.Equal(t, "invalid face num,please make sure It is valid vertice", err.Error())
+	require.Equal(t, "line 6, column 5: invalid face num,please make sure It is valid vertice: \"5\"", err.Error())
 }
 
 func Test_Parse_Triangle_Polygons(t *testing.T) {
@@ -130,7 +132,7 @@ func Test_Parse_Triangle_Groups(t *testing.T) {
 
 func Test_Parse_Triangle_Groups_Error(t *testing.T) {
 	_, err := ParseObj("test/test9.txt")
-	require.Equal(t, "invalid group columns", err.Error())
+	require.Equal(t, "line 6, column 1: invalid group columns: \"g FirstGroup InvalidColumn\"", err.Error())
 }
 
 func Test_Parse_Triangle_DefaultGroup(t *testing.T) {
@@ -175,7 +177,7 @@ func Test_Parser_Vertex_Normal(t *testing.T) {
 
 func Test_Parser_Vertex_Normal_Error(t *testing.T) {
 	_, err := ParseObj("test/vertexNormalError.txt")
-	require.Equal(t, "invalid vn columns", err.Error())
+	require.Equal(t, "line 1, column 1: invalid vn columns: \"vn 0 0 1 555\"", err.Error())
 }
 
 func Test_Parser_Face_With_Normal(t *testing.T) {
@@ -204,3 +206,155 @@ func Test_Parser_Face_With_Normal(t *testing.T) {
 	require.Equal(t, t1, t2)
 
 }
+
+func Test_Parser_Texture_Coordinates(t *testing.T) {
+	parser, err := ParseObj("test/texCoord.txt")
+	require.Nil(t, err)
+
+	require.Equal(t, []TexCoord{
+		NewTexCoord(0.5, 1),
+		NewTexCoord(0, 0),
+		NewTexCoord(1, 0),
+		NewTexCoord(1, 1),
+	}, parser.TexCoords)
+
+	children := parser.ParserGroups[0].GetChildren()
+	require.Equal(t, 4, len(children))
+
+	t1, ok := children[0].(Triangle)
+	require.True(t, ok)
+	require.True(t, t1.HasTexCoord)
+	require.Equal(t, parser.TexCoords[0], t1.T1)
+	require.Equal(t, parser.TexCoords[1], t1.T2)
+	require.Equal(t, parser.TexCoords[2], t1.T3)
+
+	t2, ok := children[1].(Triangle)
+	require.True(t, ok)
+	require.Equal(t, parser.TexCoords[0], t2.T1)
+	require.Equal(t, parser.TexCoords[2], t2.T2)
+	require.Equal(t, parser.TexCoords[3], t2.T3)
+
+	t3, ok := children[2].(SmoothTriangle)
+	require.True(t, ok)
+	require.True(t, t3.HasTexCoord)
+	require.Equal(t, parser.Normals[0], t3.N1)
+	require.Equal(t, parser.TexCoords[0], t3.T1)
+	require.Equal(t, parser.TexCoords[1], t3.T2)
+	require.Equal(t, parser.TexCoords[2], t3.T3)
+
+	t4, ok := children[3].(Triangle)
+	require.True(t, ok)
+	require.False(t, t4.HasTexCoord)
+}
+
+func Test_Parser_Texture_Coordinates_Error(t *testing.T) {
+	_, err := ParseObj("test/texCoordError.txt")
+	require.Equal(t, "line 7, column 7: invalid face num,please make sure It is valid texture coordinate: \"2/2\"", err.Error())
+
+	parser := NewParser()
+	for _, target := range []struct {
+		line string
+		ans  string
+	}{
+		{"vt", "line 1, column 1: invalid vt columns: \"vt\""},
+		{"vt 0 0 0 0", "line 2, column 1: invalid vt columns: \"vt 0 0 0 0\""},
+		{"vt a 0", "line 3, column 4: invalid number: \"a\""},
+		{"f 1/2/3/4 1 1", "line 4, column 3: invalid face single slash columns: \"1/2/3/4\""},
+	} {
+		require.Equal(t, target.ans, parser.ParseLine(target.line).Error())
+	}
+}
+
+func Test_Parser_Texture_Coordinate_Zero_Index(t *testing.T) {
+	const vertices = "v 0 1 0\nv -1 0 0\nv 1 0 0\n"
+
+	//vtがあるときの0番は範囲外
+	_, err := ParseObjReader(strings.NewReader(vertices + "vt 0 0\nf 1/0 2/1 3/1\n"))
+	require.NotNil(t, err)
+	require.Equal(t, "line 5, column 3: invalid face num,please make sure It is valid texture coordinate: \"1/0\"", err.Error())
+
+	//vtがなければ番号は無視する
+	parser, err := ParseObjReader(strings.NewReader(vertices + "f 1/0 2/0 3/0\n"))
+	require.Nil(t, err)
+
+	tri, ok := parser.ParserGroups[0].GetChildren()[0].(Triangle)
+	require.True(t, ok)
+	require.False(t, tri.HasTexCoord)
+}
+
+func Test_Parser_Assigns_Mtl_Materials(t *testing.T) {
+	parser, err := ParseObj("test/mtlObj.txt")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(parser.Materials))
+	require.Equal(t, 2, len(parser.ParserGroups))
+
+	first := parser.ParserGroups[0].GetChildren()
+	require.Equal(t, 2, len(first))
+	require.Equal(t, DefaultMaterial(), first[0].GetMaterial())
+	require.Same(t, parser.Materials["red"], first[1].GetMaterial())
+
+	//gをまたいでもusemtlは引き継ぐ
+	second := parser.ParserGroups[1].GetChildren()
+	require.Equal(t, 3, len(second))
+	require.Same(t, parser.Materials["red"], second[0].GetMaterial())
+	require.Same(t, parser.Materials["flat"], second[1].GetMaterial())
+	require.Same(t, parser.Materials["textured"], second[2].GetMaterial())
+
+	pattern, ok := second[2].GetMaterial().Pattern.(TextureMapPattern)
+	require.True(t, ok)
+	texture, ok := pattern.UVPattern.(ImageTexture)
+	require.True(t, ok)
+	require.Equal(t, 2, texture.Image.Width)
+	require.Equal(t, White, texture.UVPatternAt(0.25, 0.5))
+}
+
+func Test_Parser_Mtl_Errors(t *testing.T) {
+	_, err := ParseObj("test/mtlUnknown.txt")
+	require.Equal(t, "line 2, column 8: unknown material: \"missing\"", err.Error())
+
+	_, err = ParseObj("test/mtlError.txt")
+	require.Equal(t, files.GetFilePath("test/badColumns.mtl")+":2:1: invalid Kd columns: \"Kd 1 0\"", err.Error())
+
+	parser := NewParser()
+	require.Equal(t, "line 1, column 1: invalid usemtl columns: \"usemtl\"", parser.ParseLine("usemtl").Error())
+	require.Equal(t, "line 2, column 8: unknown material: \"red\"", parser.ParseLine("usemtl red").Error())
+	require.Nil(t, parser.ParseLine("usemtlx red"))
+}
+
+func Test_Parser_Tolerates_Tabs_And_Spaces(t *testing.T) {
+	obj := "# comment\n  v\t-1   1 0\nv -1 0 0 \r\nv\t1\t0\t0\n\ng  first\nf  1\t2   3  \n"
+
+	parser, err := ParseObjReader(strings.NewReader(obj))
+	require.Nil(t, err)
+	require.Equal(t, []calc.Tuple4{
+		calc.NewPoint(-1, 1, 0),
+		calc.NewPoint(-1, 0, 0),
+		calc.NewPoint(1, 0, 0),
+	}, parser.Vertices)
+
+	require.Equal(t, "first", parser.ParserGroups[0].Name)
+	require.Equal(t, 1, len(parser.ParserGroups[0].GetChildren()))
+}
+
+func Test_Parser_Error_Reports_Position(t *testing.T) {
+	for _, target := range []struct {
+		obj string
+		ans ParserError
+	}{
+		{"v 0 0 0\n\n\tv 1  x 0\n", ParserError{Line: 3, Column: 7, Text: "x", Message: "invalid number"}},
+		{"v 0 0 0\nf 1 1\n", ParserError{Line: 2, Column: 1, Text: "f 1 1", Message: "invalid face columns"}},
+		{"v 0 0 0\nf 1 1 x\n", ParserError{Line: 2, Column: 7, Text: "x", Message: "invalid face index"}},
+		{"v 0 0 0\nf 1 1 0\n", ParserError{Line: 2, Column: 7, Text: "0", Message: "invalid face num,please make sure It is valid vertice"}},
+		{"mtllib missing.mtl\n", ParserError{Line: 1, Column: 8, Text: "missing.mtl", Message: "open missing.mtl: no such file or directory"}},
+	} {
+		_, err := ParseObjReader(strings.NewReader(target.obj))
+		require.Equal(t, target.ans, err, target.obj)
+	}
+}
+
+func Test_Parse_Obj_Absolute_Path(t *testing.T) {
+	parser, err := ParseObj(files.GetFilePath("test/test5.txt"))
+	require.Nil(t, err)
+	require.Equal(t, 4, len(parser.Vertices))
+	require.Equal(t, 2, len(parser.ParserGroups[0].GetChildren()))
+}
@@ -0,0 +1,49 @@
+package scene
+
+import (
+	"rayGo/calc"
+)
+
+//平行投影のcamera、全てのrayが-zの向きに平行に飛ぶ
+//Widthはcanvasの横幅に写るcamera空間での幅、縦はpixelが正方形になるように決まる
+type OrthographicCamera struct {
+	HSize float64
+	VSize float64
+	Width float64
+	viewTransform
+	HalfHeight float64
+	HalfWidth  float64
+	PixelSize  float64
+}
+
+var _ Projector = OrthographicCamera{}
+
+func NewOrthographicCamera(hSize, vSize, width float64) OrthographicCamera {
+	pixelSize := width / hSize
+
+	return OrthographicCamera{
+		HSize:         hSize,
+		VSize:         vSize,
+		Width:         width,
+		viewTransform: newViewTransform(),
+		HalfWidth:     width / 2,
+		HalfHeight:    pixelSize * vSize / 2,
+		PixelSize:     pixelSize,
+	}
+}
+
+func (c OrthographicCamera) CanvasSize() (width, height int) {
+	return int(c.HSize), int(c.VSize)
+}
+
+func (c OrthographicCamera) RayForPixel(px, py float64) (Ray, error) {
+	return c.RayForPixelSample(px, py, PixelCenter)
+}
+
+//Cameraと同じくcamera空間の+xは左
+func (c OrthographicCamera) RayForPixelSample(px, py float64, sample PixelSample) (Ray, error) {
+	world_x := c.HalfWidth - (px+sample.X)*c.PixelSize
+	world_y := c.HalfHeight - (py+sample.Y)*c.PixelSize
+
+	return c.cameraRay(calc.NewPoint(world_x, world_y, 0), calc.NewVector(0, 0, -1))
+}
@@ -0,0 +1,61 @@
+package scene
+
+import (
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Create_Orthographic_Camera(t *testing.T) {
+	camera := NewOrthographicCamera(200, 100, 4)
+
+	require.Equal(t, 0.02, camera.PixelSize)
+	require.Equal(t, 2.0, camera.HalfWidth)
+	require.Equal(t, 1.0, camera.HalfHeight)
+	require.Equal(t, calc.Mat4x4(calc.Ident4x4), camera.Transform)
+
+	w, h := camera.CanvasSize()
+	require.Equal(t, 200, w)
+	require.Equal(t, 100, h)
+}
+
+func Test_Orthographic_Rays_Are_Parallel(t *testing.T) {
+	camera := NewOrthographicCamera(200, 100, 4)
+
+	for _, target := range []struct {
+		px     float64
+		py     float64
+		origin calc.Tuple4
+	}{
+		{100, 50, calc.NewPoint(-0.01, -0.01, 0)},
+		{0, 0, calc.NewPoint(1.99, 0.99, 0)},
+		{199, 99, calc.NewPoint(-1.99, -0.99, 0)},
+	} {
+		ray, err := camera.RayForPixel(target.px, target.py)
+		require.Nil(t, err)
+		require.True(t, calc.TupleCompare(target.origin, ray.Origin))
+		require.True(t, calc.TupleCompare(calc.NewVector(0, 0, -1), ray.Direction))
+	}
+}
+
+func Test_Orthographic_Camera_With_ViewTransform(t *testing.T) {
+	camera := NewOrthographicCamera(11, 11, 2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	ray, err := camera.RayForPixel(5, 5)
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewPoint(0, 0, -5), ray.Origin))
+	require.True(t, calc.TupleCompare(calc.NewVector(0, 0, 1), ray.Direction))
+
+	//遠近感がないので、隅のpixelも同じ向き
+	corner, err := camera.RayForPixel(0, 0)
+	require.Nil(t, err)
+	require.True(t, calc.TupleCompare(calc.NewVector(0, 0, 1), corner.Direction))
+
+	canvas, err := DefaultWorld().Render(camera)
+	require.Nil(t, err)
+	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), canvas.Pixels[5][5]))
+	//幅2なので球の外側の隅には何も写らない
+	require.Equal(t, Black, canvas.Pixels[0][0])
+}
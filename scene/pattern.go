@@ -8,27 +8,43 @@ type Pattern interface {
 	GetMaterial() *Material
 	SetMaterial(m *Material)
 	GetTransform() calc.Mat4x4
-	SetTransform(mat calc.Mat4x4)
+	SetTransform(mat calc.Mat4x4) error
+	GetInverse() calc.Mat4x4
 }
 
+//BaseShapeと同じくtransformはSetTransformでしか変えられない、逆行列はそのときに計算しておく
 type BasePattern struct {
-	Transform calc.Mat4x4
+	transform calc.Mat4x4
 	Material  *Material
+	inverse   calc.Mat4x4
 }
 
 func NewBasePattern() *BasePattern {
 	return &BasePattern{
-		Transform: calc.Ident4x4,
+		transform: calc.Ident4x4,
 		Material:  DefaultMaterial(),
+		inverse:   calc.Ident4x4,
 	}
 }
 
 func (b *BasePattern) GetTransform() calc.Mat4x4 {
-	return b.Transform
+	return b.transform
 }
 
-func (b *BasePattern) SetTransform(mat calc.Mat4x4) {
-	b.Transform = mat
+func (b *BasePattern) SetTransform(mat calc.Mat4x4) error {
+	inv, err := mat.Inverse()
+	if err != nil {
+		return err
+	}
+
+	b.transform = mat
+	b.inverse = inv
+
+	return nil
+}
+
+func (b *BasePattern) GetInverse() calc.Mat4x4 {
+	return b.inverse
 }
 
 func (b *BasePattern) GetMaterial() *Material {
@@ -42,20 +58,8 @@ func (b *BasePattern) SetMaterial(m *Material) {
 type PatternAt func(point calc.Tuple4) Color
 
 func (b *BasePattern) PatternAtShapeOnBase(world_point calc.Tuple4, shape Shape, fn PatternAt) (Color, error) {
-	shapeTransInv, err := shape.GetTransform().Inverse()
-
-	if err != nil {
-		return Color{}, err
-	}
-
-	object_point := shapeTransInv.MulByTuple(world_point)
-
-	patternTransInv, err := b.GetTransform().Inverse()
-	if err != nil {
-		return Color{}, err
-	}
-
-	pattern_point := patternTransInv.MulByTuple(object_point)
+	object_point := shape.GetInverse().MulByTuple(world_point)
+	pattern_point := b.inverse.MulByTuple(object_point)
 
 	return fn(pattern_point), nil
 }
@@ -1,7 +1,6 @@
 package scene
 
 import (
-	"math"
 	"rayGo/calc"
 	"rayGo/util"
 )
@@ -28,8 +27,8 @@ func (p Plane) NormalAt(worldPoint calc.Tuple4, hit Intersection) (calc.Tuple4,
 }
 
 func (p Plane) calcLocalIntersect(r Ray) (Intersections, error) {
-	//rayのy要素がEPSILON以下ならxz平面に広がるだけのPlaneとは交差しない
-	if math.Abs(r.Direction[1]) < util.DefaultEpsilon {
+	//rayのy要素がToleranceより小さければxz平面に広がるだけのPlaneとは交差しない
+	if r.isNearlyZero(r.Direction[1]) {
 		return Intersections{}, nil
 	}
 
@@ -62,3 +61,7 @@ func (p Plane) SetMaterial(m *Material) {
 func (p Plane) IsInclude(s Shape) bool {
 	return p == s
 }
+
+func (p Plane) Bounds() BoundingBox {
+	return NewBoundingBox(calc.NewPoint(-util.Inf, 0, -util.Inf), calc.NewPoint(util.Inf, 0, util.Inf))
+}
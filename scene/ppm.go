@@ -0,0 +1,111 @@
+package scene
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const maxPPMValue = 65535
+
+//書き込んだbyte数を数える
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += int64(n)
+	return n, err
+}
+
+//io.WriterTo、ASCIIのP3で書き出す
+func (c *Canvas) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := c.WritePPM(cw)
+
+	return cw.count, err
+}
+
+//ASCIIのP3を一行ずつ書き出す、全体を文字列にしないので大きな画像でもmemoryを使わない
+func (c *Canvas) WritePPM(w io.Writer, options ...ImageOption) error {
+	opts := newImageOptions(options...)
+	if err := opts.validateMaxValue(); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "P3\n%d %d\n%d\n", c.Width, c.Height, opts.MaxValue)
+
+	for i := 0; i < c.Height; i++ {
+		writeP3Row(buf, c.Pixels[i], opts)
+	}
+
+	return buf.Flush()
+}
+
+//red,green,blueと足していって70charを超えるところで改行する
+//値の幅は桁数に関係なくmaxValueの桁数で数えるので、255なら一行に17個まで
+func writeP3Row(buf *bufio.Writer, row []Color, opts *ImageOptions) {
+	digits := len(strconv.Itoa(opts.MaxValue))
+	threshold := 69
+	count := 0
+
+	for _, pixel := range row {
+		values := opts.quantize(pixel)
+		for i := 0; i <= 2; i++ {
+			if count != 0 {
+				if (digits+1)*count+digits > threshold {
+					buf.WriteByte('\n')
+					count = 0
+				} else {
+					buf.WriteByte(' ')
+				}
+			}
+
+			buf.WriteString(strconv.Itoa(values[i]))
+			count++
+		}
+	}
+
+	buf.WriteByte('\n')
+}
+
+//binaryのP6を一行ずつ書き出す、maxValueが256以上ならbig endianの2byte
+func (c *Canvas) WriteBinaryPPM(w io.Writer, options ...ImageOption) error {
+	opts := newImageOptions(options...)
+	if err := opts.validateMaxValue(); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "P6\n%d %d\n%d\n", c.Width, c.Height, opts.MaxValue)
+
+	bytesPerValue := 1
+	if opts.MaxValue > 255 {
+		bytesPerValue = 2
+	}
+
+	row := make([]byte, c.Width*3*bytesPerValue)
+	for i := 0; i < c.Height; i++ {
+		pos := 0
+		for _, pixel := range c.Pixels[i] {
+			for _, value := range opts.quantize(pixel) {
+				if bytesPerValue == 2 {
+					row[pos] = byte(value >> 8)
+					pos++
+				}
+				row[pos] = byte(value)
+				pos++
+			}
+		}
+
+		if _, err := buf.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
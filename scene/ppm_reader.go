@@ -0,0 +1,263 @@
+package scene
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+//大きすぎるsizeはpixelを読む前にエラーにする
+//8192x4096の環境mapまで読める
+//P6はdataが揃っているのを確かめてから、P3は読めたpixelの分だけ確保する
+const maxPPMPixels = 1 << 25
+
+type PPMError struct {
+	message string
+}
+
+func (p PPMError) Error() string {
+	return p.message
+}
+
+func NewPPMError(message string) PPMError {
+	return PPMError{
+		message: message,
+	}
+}
+
+//headerとP3のpixelは空白区切りのtoken、#から行末まではcomment
+type ppmReader struct {
+	r        *bufio.Reader
+	transfer Transfer
+}
+
+func isPPMSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f'
+}
+
+func (p *ppmReader) skipSpaceAndComments() error {
+	for {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if b == '#' {
+			if _, err := p.r.ReadString('\n'); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isPPMSpace(b) {
+			return p.r.UnreadByte()
+		}
+	}
+}
+
+//何を読んでいたかはエラーのときだけnameを呼んで作る
+func (p *ppmReader) token(name func() string) (string, error) {
+	if err := p.skipSpaceAndComments(); err != nil {
+		if err == io.EOF {
+			return "", NewPPMError("unexpected end of file reading " + name())
+		}
+		return "", err
+	}
+
+	var buf strings.Builder
+	for {
+		b, err := p.r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if isPPMSpace(b) || b == '#' {
+			if err := p.r.UnreadByte(); err != nil {
+				return "", err
+			}
+			break
+		}
+
+		buf.WriteByte(b)
+	}
+
+	return buf.String(), nil
+}
+
+func constName(name string) func() string {
+	return func() string {
+		return name
+	}
+}
+
+func (p *ppmReader) integer(name func() string, min, max int) (int, error) {
+	tok, err := p.token(name)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, NewPPMError(fmt.Sprintf("invalid %s: %q", name(), tok))
+	}
+
+	if value < min || max < value {
+		return 0, NewPPMError(fmt.Sprintf("%s out of range [%d, %d]: %d", name(), min, max, value))
+	}
+
+	return value, nil
+}
+
+//P3(ASCII)とP6(binary)を読む、各channelはmaxvalで割って0~1にする
+//ImageTransferを指定するとそのdecodeでlinearに戻す
+func ReadPPM(r io.Reader, options ...ImageOption) (*Canvas, error) {
+	opts := newImageOptions(options...)
+	transfer := opts.Transfer
+	if transfer == nil {
+		transfer = LinearTransfer{}
+	}
+
+	p := &ppmReader{
+		r:        bufio.NewReader(r),
+		transfer: transfer,
+	}
+
+	magic, err := p.token(constName("magic number"))
+	if err != nil {
+		return nil, err
+	}
+
+	if magic != "P3" && magic != "P6" {
+		return nil, NewPPMError(fmt.Sprintf("unsupported magic number: %q", magic))
+	}
+
+	width, err := p.integer(constName("width"), 1, int(^uint(0)>>1))
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := p.integer(constName("height"), 1, int(^uint(0)>>1))
+	if err != nil {
+		return nil, err
+	}
+
+	//width*heightがoverflowしないように割り算で比べる
+	if width > maxPPMPixels/height {
+		return nil, NewPPMError(fmt.Sprintf("image too large: %dx%d (at most %d pixels)", width, height, maxPPMPixels))
+	}
+
+	maxValue, err := p.integer(constName("maxval"), 1, maxPPMValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if magic == "P6" {
+		//maxvalの後ろはちょうど一つの空白で、その次からbinary
+		b, err := p.r.ReadByte()
+		if err != nil || !isPPMSpace(b) {
+			return nil, NewPPMError("missing whitespace before binary pixel data")
+		}
+
+		return p.readBinary(width, height, maxValue)
+	}
+
+	return p.readASCII(width, height, maxValue)
+}
+
+//短いfileでheaderのsize分を確保しないように、rowもpixelも読めた分だけappendする
+func (p *ppmReader) readASCII(width, height, maxValue int) (*Canvas, error) {
+	var pixels [][]Color
+
+	for y := 0; y < height; y++ {
+		var row []Color
+
+		for x := 0; x < width; x++ {
+			name := func() string {
+				return fmt.Sprintf("pixel (%d, %d)", x, y)
+			}
+
+			var rgb [3]float64
+			for i := 0; i < 3; i++ {
+				value, err := p.integer(name, 0, maxValue)
+				if err != nil {
+					return nil, err
+				}
+
+				rgb[i] = p.transfer.Decode(float64(value) / float64(maxValue))
+			}
+
+			row = append(row, NewColor(rgb[0], rgb[1], rgb[2]))
+		}
+
+		pixels = append(pixels, row)
+	}
+
+	return &Canvas{
+		Width:  width,
+		Height: height,
+		Pixels: pixels,
+	}, nil
+}
+
+//maxvalが256以上のときは2byteのbig endian
+//pixelが全部揃っているのを確かめてからcanvasを確保する
+func (p *ppmReader) readBinary(width, height, maxValue int) (*Canvas, error) {
+	bytesPerValue := 1
+	if maxValue > 255 {
+		bytesPerValue = 2
+	}
+
+	rowSize := width * 3 * bytesPerValue
+
+	//ReadAllは読めた分だけ確保するので、短いfileでheaderのsize分を確保することはない
+	data, err := ioutil.ReadAll(io.LimitReader(p.r, int64(rowSize)*int64(height)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < rowSize*height {
+		return nil, NewPPMError(fmt.Sprintf("unexpected end of file reading row %d", len(data)/rowSize))
+	}
+
+	canvas := NewCanvas(width, height)
+	if err := p.decodeBinary(canvas, data, rowSize, bytesPerValue, maxValue); err != nil {
+		return nil, err
+	}
+
+	return canvas, nil
+}
+
+func (p *ppmReader) decodeBinary(canvas *Canvas, data []byte, rowSize, bytesPerValue, maxValue int) error {
+	for y := 0; y < canvas.Height; y++ {
+		row := data[y*rowSize : (y+1)*rowSize]
+
+		pos := 0
+		for x := 0; x < canvas.Width; x++ {
+			var rgb [3]float64
+			for i := 0; i < 3; i++ {
+				value := int(row[pos])
+				pos++
+				if bytesPerValue == 2 {
+					value = value<<8 | int(row[pos])
+					pos++
+				}
+
+				if value > maxValue {
+					return NewPPMError(fmt.Sprintf("pixel (%d, %d) out of range [0, %d]: %d", x, y, maxValue, value))
+				}
+
+				rgb[i] = p.transfer.Decode(float64(value) / float64(maxValue))
+			}
+
+			canvas.WritePixel(x, y, NewColor(rgb[0], rgb[1], rgb[2]))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package scene
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Read_PPM_Wrong_Magic_Number(t *testing.T) {
+	_, err := ReadPPM(strings.NewReader("P32\n1 1\n255\n0 0 0\n"))
+	require.IsType(t, PPMError{}, err)
+}
+
+func Test_Read_PPM_Size(t *testing.T) {
+	c, err := ReadPPM(strings.NewReader("P3\n10 2\n255\n" + strings.Repeat("0 0 0\n", 20)))
+	require.Nil(t, err)
+	require.Equal(t, 10, c.Width)
+	require.Equal(t, 2, c.Height)
+}
+
+func Test_Read_PPM_Pixel_Data(t *testing.T) {
+	ppm := `P3
+4 3
+255
+255 127 0  0 127 255  127 255 0  255 255 255
+0 0 0  255 0 0  0 255 0  0 0 255
+255 255 0  0 255 255  255 0 255  127 127 127
+`
+	c, err := ReadPPM(strings.NewReader(ppm))
+	require.Nil(t, err)
+
+	for _, target := range []struct {
+		x   int
+		y   int
+		ans Color
+	}{
+		{0, 0, NewColor(1, 0.498, 0)},
+		{1, 0, NewColor(0, 0.498, 1)},
+		{2, 0, NewColor(0.498, 1, 0)},
+		{3, 0, NewColor(1, 1, 1)},
+		{0, 1, NewColor(0, 0, 0)},
+		{1, 1, NewColor(1, 0, 0)},
+		{2, 1, NewColor(0, 1, 0)},
+		{3, 1, NewColor(0, 0, 1)},
+		{0, 2, NewColor(1, 1, 0)},
+		{1, 2, NewColor(0, 1, 1)},
+		{2, 2, NewColor(1, 0, 1)},
+		{3, 2, NewColor(0.498, 0.498, 0.498)},
+	} {
+		require.True(t, colorCompareLoose(target.ans, c.Pixels[target.y][target.x]), target)
+	}
+}
+
+//255で割ると小数点第五位まではそろわないので少し緩く比べる
+func colorCompareLoose(c1, c2 Color) bool {
+	return colorDistance(c1, c2) < 0.001
+}
+
+func Test_Read_PPM_Ignores_Comments(t *testing.T) {
+	ppm := `P3
+# this is a comment
+2 1
+# this, too
+255
+# another comment
+255 255 255
+# oh, no, comments in the pixel data!
+255 0 255
+`
+	c, err := ReadPPM(strings.NewReader(ppm))
+	require.Nil(t, err)
+	require.Equal(t, NewColor(1, 1, 1), c.Pixels[0][0])
+	require.Equal(t, NewColor(1, 0, 1), c.Pixels[0][1])
+}
+
+func Test_Read_PPM_RGB_Triple_Spans_Lines(t *testing.T) {
+	ppm := "P3\n1 1\n255\n51\n153\n\n204\n"
+	c, err := ReadPPM(strings.NewReader(ppm))
+	require.Nil(t, err)
+	require.True(t, colorCompare(NewColor(0.2, 0.6, 0.8), c.Pixels[0][0]))
+}
+
+func Test_Read_PPM_Respects_Scale(t *testing.T) {
+	ppm := "P3 2 2 100\n100 100 100  50 50 50\n75 50 25  0 0 0\n"
+	c, err := ReadPPM(strings.NewReader(ppm))
+	require.Nil(t, err)
+	require.True(t, colorCompare(NewColor(0.75, 0.5, 0.25), c.Pixels[1][0]))
+}
+
+func Test_Read_Binary_PPM(t *testing.T) {
+	data := append([]byte("P6\n# binary\n2 1\n255\n"), 255, 128, 0, 51, 102, 153)
+	c, err := ReadPPM(bytes.NewReader(data))
+	require.Nil(t, err)
+	require.True(t, colorCompareLoose(NewColor(1, 0.502, 0), c.Pixels[0][0]))
+	require.True(t, colorCompare(NewColor(0.2, 0.4, 0.6), c.Pixels[0][1]))
+}
+
+func Test_Read_PPM_Round_Trip(t *testing.T) {
+	c := NewCanvas(7, 3)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			c.WritePixel(x, y, NewColor(float64(x)/6, float64(y)/2, 0.5))
+		}
+	}
+
+	for _, target := range []struct {
+		title string
+		write func(*bytes.Buffer) error
+	}{
+		{"P3", func(buf *bytes.Buffer) error { return c.WritePPM(buf) }},
+		{"P6", func(buf *bytes.Buffer) error { return c.WriteBinaryPPM(buf) }},
+		{"P3 16bit", func(buf *bytes.Buffer) error { return c.WritePPM(buf, ImageMaxValue(65535)) }},
+		{"P6 16bit", func(buf *bytes.Buffer) error { return c.WriteBinaryPPM(buf, ImageMaxValue(65535)) }},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.Nil(t, target.write(&buf))
+
+			read, err := ReadPPM(&buf)
+			require.Nil(t, err)
+			require.Equal(t, c.Width, read.Width)
+			require.Equal(t, c.Height, read.Height)
+
+			for y := 0; y < c.Height; y++ {
+				for x := 0; x < c.Width; x++ {
+					require.True(t, colorDistance(c.Pixels[y][x], read.Pixels[y][x]) < 1.0/255)
+				}
+			}
+		})
+	}
+}
+
+func Test_Read_PPM_Malformed(t *testing.T) {
+	for _, target := range []struct {
+		title string
+		input string
+	}{
+		{"empty", ""},
+		{"missing height", "P3\n2\n"},
+		{"negative width", "P3\n-2 1\n255\n"},
+		{"maxval too large", "P3\n1 1\n65536\n0 0 0\n"},
+		{"not a number", "P3\n1 1\n255\n0 x 0\n"},
+		{"value above maxval", "P3\n1 1\n100\n0 101 0\n"},
+		{"too few pixels", "P3\n2 1\n255\n0 0 0\n"},
+		{"truncated binary", "P6\n2 1\n255\n\x00\x00\x00"},
+		{"binary above maxval", "P6\n1 1\n100\n\x00\xff\x00"},
+		//headerだけでpixel数分を確保しない
+		{"too large", "P3 100000000 100000000 255"},
+		{"too large binary", "P6 100000000 100000000 255 "},
+		{"size overflows", "P3 9223372036854775807 9223372036854775807 255"},
+		{"truncated large binary", "P6 4096 4096 255 \x00\x00\x00"},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			_, err := ReadPPM(strings.NewReader(target.input))
+			require.IsType(t, PPMError{}, err, err)
+		})
+	}
+}
+
+func Test_Read_PPM_Truncated_ASCII_Does_Not_Allocate_Header_Size(t *testing.T) {
+	var before, after runtime.MemStats
+
+	//headerは5792x5792(上限ちょうど)だがpixelは一つだけ
+	runtime.ReadMemStats(&before)
+	_, err := ReadPPM(strings.NewReader("P3 5792 5792 255 0 0 0"))
+	runtime.ReadMemStats(&after)
+
+	require.IsType(t, PPMError{}, err)
+	require.Equal(t, "unexpected end of file reading pixel (1, 0)", err.Error())
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
+
+func Test_Load_Canvas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "canvas")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewCanvas(3, 2)
+	c.WritePixel(1, 1, NewColor(1, 0, 0))
+
+	path := filepath.Join(dir, "in.ppm")
+	require.Nil(t, c.Save(path))
+
+	read, err := LoadCanvas(path)
+	require.Nil(t, err)
+	require.Equal(t, c.Pixels, read.Pixels)
+
+	_, err = LoadCanvas(filepath.Join(dir, "in.gif"))
+	require.IsType(t, ImageFormatError{}, err)
+}
@@ -0,0 +1,104 @@
+package scene
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Write_PPM_Streams_Same_As_ToPPM(t *testing.T) {
+	c := NewCanvas(10, 2)
+	for i := 0; i < c.Height; i++ {
+		for j := 0; j < c.Width; j++ {
+			c.WritePixel(j, i, NewColor(1, 0.8, 0.6))
+		}
+	}
+	c.WritePixel(3, 1, NewColor(0, 0.1, 0))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePPM(&buf))
+	require.Equal(t, c.ToPPM(), buf.String())
+
+	var to bytes.Buffer
+	n, err := c.WriteTo(&to)
+	require.Nil(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+	require.Equal(t, buf.String(), to.String())
+}
+
+func Test_Write_PPM_With_MaxValue(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.WritePixel(0, 0, NewColor(1.5, 0.5, 0))
+	c.WritePixel(1, 0, NewColor(0.25, 1, -1))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePPM(&buf, ImageMaxValue(15)))
+	require.Equal(t, "P3\n2 1\n15\n15 8 0 4 15 0\n", buf.String())
+
+	buf.Reset()
+	require.Nil(t, c.WritePPM(&buf, ImageMaxValue(65535)))
+	require.Equal(t, "P3\n2 1\n65535\n65535 32768 0 16384 65535 0\n", buf.String())
+}
+
+func Test_Write_PPM_16bit_Line_Length(t *testing.T) {
+	c := NewCanvas(10, 1)
+	for j := 0; j < c.Width; j++ {
+		c.WritePixel(j, 0, NewColor(1, 0.8, 0.6))
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePPM(&buf, ImageMaxValue(65535)))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Equal(t, "65535 52428 39321 65535 52428 39321 65535 52428 39321 65535 52428", lines[3])
+
+	values := 0
+	for _, line := range lines[3:] {
+		require.True(t, len(line) <= 70, line)
+		require.False(t, strings.HasSuffix(line, " "))
+		values += len(strings.Fields(line))
+	}
+	require.Equal(t, 30, values)
+}
+
+func Test_Write_Binary_PPM(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c.WritePixel(0, 0, NewColor(1.5, 0.5, 0))
+	c.WritePixel(1, 1, NewColor(0.2, 0.4, 0.6))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WriteBinaryPPM(&buf))
+
+	header := "P6\n2 2\n255\n"
+	require.Equal(t, header, buf.String()[:len(header)])
+	require.Equal(t, []byte{
+		255, 128, 0, 0, 0, 0,
+		0, 0, 0, 51, 102, 153,
+	}, buf.Bytes()[len(header):])
+}
+
+func Test_Write_Binary_PPM_16bit(t *testing.T) {
+	c := NewCanvas(1, 1)
+	c.WritePixel(0, 0, NewColor(1, 0.5, 0))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WriteBinaryPPM(&buf, ImageMaxValue(65535)))
+
+	header := "P6\n1 1\n65535\n"
+	require.Equal(t, header, buf.String()[:len(header)])
+	//big endian
+	require.Equal(t, []byte{0xff, 0xff, 0x80, 0x00, 0x00, 0x00}, buf.Bytes()[len(header):])
+}
+
+func Test_Write_PPM_Invalid_MaxValue(t *testing.T) {
+	c := NewCanvas(1, 1)
+
+	for _, maxValue := range []int{0, -1, 65536} {
+		var buf bytes.Buffer
+		require.IsType(t, ImageFormatError{}, c.WritePPM(&buf, ImageMaxValue(maxValue)))
+		require.IsType(t, ImageFormatError{}, c.WriteBinaryPPM(&buf, ImageMaxValue(maxValue)))
+		require.Equal(t, 0, buf.Len())
+	}
+}
@@ -0,0 +1,51 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//splitmix64、同じseedなら同じ列を返すので並列Renderでも結果が変わらない
+//goroutine間で共有せずに使う箇所ごとにNewRandomすること
+type Random struct {
+	state uint64
+}
+
+func NewRandom(seed uint64) *Random {
+	return &Random{
+		state: seed,
+	}
+}
+
+func (r *Random) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
+
+//[0,1)
+func (r *Random) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+//pointごとに違うseedを作る、同じpointなら何度呼んでも同じseed
+func hashPoint(seed uint64, p calc.Tuple4) uint64 {
+	h := NewRandom(seed).Uint64()
+	for i := 0; i < 3; i++ {
+		h = NewRandom(h ^ math.Float64bits(p[i])).Uint64()
+	}
+
+	return h
+}
+
+//pixelごとのseed、どのworkerがどの順番で描いても同じ乱数列になる
+func hashPixel(seed uint64, x, y int) uint64 {
+	h := NewRandom(seed).Uint64()
+	h = NewRandom(h ^ uint64(x)).Uint64()
+
+	return NewRandom(h ^ uint64(y)).Uint64()
+}
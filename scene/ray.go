@@ -1,13 +1,18 @@
 package scene
 
 import (
+	"math"
 	"rayGo/calc"
+	"rayGo/util"
 )
 
 //Origin -> Point,Direction -> Vector
+//Toleranceは交差判定で0とみなす幅、renderではRenderSettings.Epsilonが入る
+//0のときはutil.EPSILONを使う
 type Ray struct {
 	Origin    calc.Tuple4
 	Direction calc.Tuple4
+	Tolerance float64
 }
 
 func NewRay(origin, direction calc.Tuple4) Ray {
@@ -22,9 +27,27 @@ func (r Ray) Position(t float64) calc.Tuple4 {
 	return calc.AddTuple(r.Origin, calc.MulTupleByScalar(t, r.Direction))
 }
 
+//object空間に移しても同じToleranceで判定する
 func (r Ray) Transform(mat calc.Mat4x4) Ray {
 	return Ray{
 		Origin:    mat.MulByTuple(r.Origin),
 		Direction: mat.MulByTuple(r.Direction),
+		Tolerance: r.Tolerance,
 	}
 }
+
+func (r Ray) tolerance() float64 {
+	if r.Tolerance == 0 {
+		return util.EPSILON.Value()
+	}
+
+	return r.Tolerance
+}
+
+func (r Ray) isNearlyZero(num float64) bool {
+	return isNearlyZeroWithin(num, r.tolerance())
+}
+
+func isNearlyZeroWithin(num, tolerance float64) bool {
+	return math.Abs(num) < tolerance
+}
@@ -1 +1,302 @@
 package scene
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const DefaultTileSize = 16
+
+type RenderOptions struct {
+	Workers  int
+	TileSize int
+	Progress ProgressFunc
+	Seed     uint64
+	//Render中はここにcopyしたものを使うので、呼び出し元が後で書き換えても影響しない
+	Settings RenderSettings
+}
+
+type RenderOption func(*RenderOptions)
+
+func RenderWorkers(n int) RenderOption {
+	return func(o *RenderOptions) {
+		o.Workers = n
+	}
+}
+
+func RenderTileSize(size int) RenderOption {
+	return func(o *RenderOptions) {
+		o.TileSize = size
+	}
+}
+
+//Tileが一つ終わるごとに呼ばれる、呼び出しは直列化されている
+func RenderProgress(fn ProgressFunc) RenderOption {
+	return func(o *RenderOptions) {
+		o.Progress = fn
+	}
+}
+
+//pixelごとのsampleの取り方、指定しなければpixelの中心に一本だけ飛ばす
+func RenderSampler(sampler Sampler) RenderOption {
+	return func(o *RenderOptions) {
+		o.Settings.Sampler = sampler
+	}
+}
+
+//JitteredSamplerなどの乱数のseed、同じseedなら同じ画像になる
+func RenderSeed(seed uint64) RenderOption {
+	return func(o *RenderOptions) {
+		o.Seed = seed
+	}
+}
+
+//反射と屈折の両方の深さをまとめて指定する
+func RenderMaxDepth(depth int) RenderOption {
+	return func(o *RenderOptions) {
+		o.Settings.MaxReflectionDepth = depth
+		o.Settings.MaxRefractionDepth = depth
+	}
+}
+
+//settingsをまるごと差し替える、後に書いたRenderSamplerなどはその上に上書きされる
+func RenderWithSettings(settings RenderSettings) RenderOption {
+	return func(o *RenderOptions) {
+		o.Settings = settings
+	}
+}
+
+func newRenderOptions(options ...RenderOption) *RenderOptions {
+	defaultOptions := &RenderOptions{
+		runtime.NumCPU(),
+		DefaultTileSize,
+		nil,
+		0,
+		*DefaultRenderSettings(),
+	}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
+
+	if defaultOptions.Workers < 1 {
+		defaultOptions.Workers = 1
+	}
+
+	if defaultOptions.TileSize < 1 {
+		defaultOptions.TileSize = DefaultTileSize
+	}
+
+	if defaultOptions.Settings.MaxReflectionDepth < 0 {
+		defaultOptions.Settings.MaxReflectionDepth = 0
+	}
+
+	if defaultOptions.Settings.MaxRefractionDepth < 0 {
+		defaultOptions.Settings.MaxRefractionDepth = 0
+	}
+
+	if defaultOptions.Settings.Background == nil {
+		defaultOptions.Settings.Background = NewSolidBackground(Black)
+	}
+
+	if defaultOptions.Settings.Sampler == nil {
+		defaultOptions.Settings.Sampler = CenterSampler{}
+	}
+
+	return defaultOptions
+}
+
+type Progress struct {
+	CompletedTiles  int
+	TotalTiles      int
+	CompletedPixels int
+	TotalPixels     int
+	Elapsed         time.Duration
+	//これまでのpixelあたりの時間から残りを見積もる
+	ETA time.Duration
+}
+
+func (p Progress) Fraction() float64 {
+	if p.TotalPixels == 0 {
+		return 1
+	}
+
+	return float64(p.CompletedPixels) / float64(p.TotalPixels)
+}
+
+type ProgressFunc func(Progress)
+
+type progressTracker struct {
+	lock     sync.Mutex
+	start    time.Time
+	progress Progress
+	fn       ProgressFunc
+}
+
+func newProgressTracker(totalTiles, totalPixels int, fn ProgressFunc) *progressTracker {
+	return &progressTracker{
+		start: time.Now(),
+		progress: Progress{
+			TotalTiles:  totalTiles,
+			TotalPixels: totalPixels,
+		},
+		fn: fn,
+	}
+}
+
+func (pt *progressTracker) complete(tile Tile) {
+	if pt.fn == nil {
+		return
+	}
+
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	pt.progress.CompletedTiles++
+	pt.progress.CompletedPixels += tile.Width * tile.Height
+	pt.progress.Elapsed = time.Since(pt.start)
+
+	remaining := pt.progress.TotalPixels - pt.progress.CompletedPixels
+	perPixel := pt.progress.Elapsed / time.Duration(pt.progress.CompletedPixels)
+	pt.progress.ETA = perPixel * time.Duration(remaining)
+
+	pt.fn(pt.progress)
+}
+
+//Canvasを分割したbucket、右端と下端のTileはTileSizeより小さくなることがある
+type Tile struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+//左上から行順にTileを並べる
+func SplitTiles(width, height, tileSize int) []Tile {
+	var tiles []Tile
+
+	for y := 0; y < height; y += tileSize {
+		for x := 0; x < width; x += tileSize {
+			tiles = append(tiles, Tile{
+				X:      x,
+				Y:      y,
+				Width:  minInt(tileSize, width-x),
+				Height: minInt(tileSize, height-y),
+			})
+		}
+	}
+
+	return tiles
+}
+
+//各Tileは別々のpixelにしか書き込まないのでcanvasへのlockは不要
+//cancelされたら行の途中で打ち切る
+func (w *World) renderTile(ctx context.Context, camera Projector, canvas *Canvas, tile Tile, opts *RenderOptions) error {
+	settings := &opts.Settings
+	for y := tile.Y; y < tile.Y+tile.Height; y++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		for x := tile.X; x < tile.X+tile.Width; x++ {
+			px, py := float64(x), float64(y)
+			trace := func(sample PixelSample) (Color, error) {
+				ray, err := camera.RayForPixelSample(px, py, sample)
+				if err != nil {
+					return Color{}, err
+				}
+
+				return w.ColorAt(ray, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+			}
+
+			rng := NewRandom(hashPixel(opts.Seed, x, y))
+			color, err := settings.Sampler.SamplePixel(rng, trace)
+			if err != nil {
+				return err
+			}
+
+			canvas.WritePixel(x, y, color)
+		}
+	}
+
+	return nil
+}
+
+//Render中はWorldやShapeを書き換えないこと
+//Intersect,ColorAtは読み取りのみなので複数のgoroutineから呼んでも安全
+func (w *World) Render(camera Projector, options ...RenderOption) (*Canvas, error) {
+	return w.RenderContext(context.Background(), camera, options...)
+}
+
+//ctxがcancelされたときは途中まで書き込んだCanvasとctx.Err()を返す
+//Tileの描画でerrorが起きたときはCanvasを返さない
+func (w *World) RenderContext(ctx context.Context, camera Projector, options ...RenderOption) (*Canvas, error) {
+	opts := newRenderOptions(options...)
+	canvas := NewCanvas(camera.CanvasSize())
+
+	allTiles := SplitTiles(canvas.Width, canvas.Height, opts.TileSize)
+	tracker := newProgressTracker(len(allTiles), canvas.Width*canvas.Height, opts.Progress)
+
+	//Tileのerrorで他のworkerも止めるためにctxをwrapする
+	renderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tiles := make(chan Tile)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var renderErr error
+
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tile := range tiles {
+				if err := w.renderTile(renderCtx, camera, canvas, tile, opts); err != nil {
+					//最初のerrorだけを返し、残りのTileは捨てる
+					once.Do(func() {
+						renderErr = err
+						cancel()
+					})
+					continue
+				}
+
+				if renderCtx.Err() == nil {
+					tracker.complete(tile)
+				}
+			}
+		}()
+	}
+
+	func() {
+		defer close(tiles)
+		for _, tile := range allTiles {
+			select {
+			case tiles <- tile:
+			case <-renderCtx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	if renderErr != nil {
+		return nil, renderErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return canvas, err
+	}
+
+	return canvas, nil
+}
@@ -0,0 +1,196 @@
+package scene
+
+import (
+	"context"
+	"errors"
+	"math"
+	"rayGo/calc"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Split_Tiles(t *testing.T) {
+	tiles := SplitTiles(10, 5, 4)
+
+	require.Equal(t, 6, len(tiles))
+	require.Equal(t, Tile{0, 0, 4, 4}, tiles[0])
+	require.Equal(t, Tile{8, 0, 2, 4}, tiles[2])
+	require.Equal(t, Tile{8, 4, 2, 1}, tiles[5])
+
+	//全てのpixelがちょうど一度だけ含まれる
+	covered := make(map[[2]int]int)
+	for _, tile := range tiles {
+		for y := tile.Y; y < tile.Y+tile.Height; y++ {
+			for x := tile.X; x < tile.X+tile.Width; x++ {
+				covered[[2]int{x, y}]++
+			}
+		}
+	}
+
+	require.Equal(t, 50, len(covered))
+	for _, count := range covered {
+		require.Equal(t, 1, count)
+	}
+}
+
+func Test_Parallel_Render_Matches_Serial(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(23, 17, math.Pi/2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	serial, err := w.Render(camera, RenderWorkers(1), RenderTileSize(1000))
+	require.Nil(t, err)
+
+	for _, target := range []struct {
+		title    string
+		workers  int
+		tileSize int
+	}{
+		{"many workers with small tiles", 8, 3},
+		{"few workers with default tiles", 2, DefaultTileSize},
+		{"tile larger than canvas", 4, 64},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			canvas, err := w.Render(camera, RenderWorkers(target.workers), RenderTileSize(target.tileSize))
+			require.Nil(t, err)
+			require.Equal(t, serial.Pixels, canvas.Pixels)
+		})
+	}
+}
+
+//決まったpixelでrayを作れずにerrorを返すcamera
+type failingProjector struct {
+	Camera
+	failX, failY float64
+}
+
+func (p failingProjector) RayForPixelSample(px, py float64, sample PixelSample) (Ray, error) {
+	if px == p.failX && py == p.failY {
+		return Ray{}, errors.New("cannot create ray")
+	}
+
+	return p.Camera.RayForPixelSample(px, py, sample)
+}
+
+func Test_Render_Returns_Error(t *testing.T) {
+	w := DefaultWorld()
+	camera := failingProjector{NewCamera(11, 11, math.Pi/2), 7, 3}
+
+	canvas, err := w.Render(camera, RenderWorkers(4), RenderTileSize(2))
+	require.NotNil(t, err)
+	require.Nil(t, canvas)
+}
+
+func Test_Render_Reports_Progress(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(10, 10, math.Pi/2)
+
+	var reports []Progress
+	_, err := w.Render(camera, RenderWorkers(3), RenderTileSize(4), RenderProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	require.Nil(t, err)
+
+	require.Equal(t, 9, len(reports))
+	for i, p := range reports {
+		require.Equal(t, i+1, p.CompletedTiles)
+		require.Equal(t, 9, p.TotalTiles)
+		require.Equal(t, 100, p.TotalPixels)
+	}
+
+	last := reports[len(reports)-1]
+	require.Equal(t, 100, last.CompletedPixels)
+	require.Equal(t, 1.0, last.Fraction())
+	require.Equal(t, int64(0), int64(last.ETA))
+}
+
+func Test_Render_Context_Canceled_Returns_Partial_Canvas(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(11, 11, math.Pi/2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	completed := 0
+	canvas, err := w.RenderContext(ctx, camera, RenderWorkers(1), RenderTileSize(1), RenderProgress(func(p Progress) {
+		completed = p.CompletedTiles
+		//半分描いたところで止める
+		if p.CompletedTiles == 61 {
+			cancel()
+		}
+	}))
+
+	require.Equal(t, context.Canceled, err)
+	require.NotNil(t, canvas)
+	require.Equal(t, 61, completed)
+
+	//中心までは描画済み、最後のpixelは未描画
+	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), canvas.Pixels[5][5]))
+	require.Equal(t, Black, canvas.Pixels[10][10])
+}
+
+func Test_Render_Context_Already_Canceled(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(5, 5, math.Pi/2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	canvas, err := w.RenderContext(ctx, camera)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 5, canvas.Width)
+}
+
+func Test_Render_Settings_Options(t *testing.T) {
+	require.Equal(t, *DefaultRenderSettings(), newRenderOptions().Settings)
+
+	opts := newRenderOptions(RenderMaxDepth(2))
+	require.Equal(t, 2, opts.Settings.MaxReflectionDepth)
+	require.Equal(t, 2, opts.Settings.MaxRefractionDepth)
+
+	opts = newRenderOptions(RenderMaxDepth(-1))
+	require.Equal(t, 0, opts.Settings.MaxReflectionDepth)
+	require.Equal(t, 0, opts.Settings.MaxRefractionDepth)
+
+	settings := *DefaultRenderSettings()
+	settings.Background = NewSolidBackground(Blue)
+	settings.Sampler = nil
+	opts = newRenderOptions(RenderWithSettings(settings), RenderMaxDepth(3))
+	require.Equal(t, NewSolidBackground(Blue), opts.Settings.Background)
+	require.Equal(t, 3, opts.Settings.MaxReflectionDepth)
+	require.Equal(t, CenterSampler{}, opts.Settings.Sampler)
+}
+
+//同じWorldを違う設定で同時にrenderしてもそれぞれの設定で描かれる
+func Test_Concurrent_Renders_With_Different_Settings(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(9, 9, math.Pi/2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	backgrounds := []Color{Red, Green, Blue, White}
+	canvases := make([]*Canvas, len(backgrounds))
+	errs := make([]error, len(backgrounds))
+
+	var wg sync.WaitGroup
+	for i, background := range backgrounds {
+		wg.Add(1)
+		go func(i int, background Color) {
+			defer wg.Done()
+
+			settings := *DefaultRenderSettings()
+			settings.Background = NewSolidBackground(background)
+			canvases[i], errs[i] = w.Render(camera, RenderWithSettings(settings), RenderWorkers(2), RenderTileSize(2))
+		}(i, background)
+	}
+	wg.Wait()
+
+	for i, background := range backgrounds {
+		require.Nil(t, errs[i])
+		//角はどのobjectにも当たらない
+		require.Equal(t, background, canvases[i].Pixels[0][0])
+		require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), canvases[i].Pixels[4][4]))
+	}
+}
@@ -0,0 +1,231 @@
+package scene
+
+import (
+	"math"
+)
+
+//pixelの中のどこを通るrayを飛ばすか、左上が(0,0)で右下が(1,1)
+//LensU,LensVはCameraのlensの上のどこからrayを出すか(0~1)、Apertureが0なら使われない
+type PixelSample struct {
+	X     float64
+	Y     float64
+	LensU float64
+	LensV float64
+}
+
+var PixelCenter = PixelSample{0.5, 0.5, 0.5, 0.5}
+
+//rngがnilのときはlensの中心
+func newPixelSample(x, y float64, rng *Random) PixelSample {
+	lensU, lensV := 0.5, 0.5
+	if rng != nil {
+		lensU, lensV = rng.Float64(), rng.Float64()
+	}
+
+	return PixelSample{
+		X:     x,
+		Y:     y,
+		LensU: lensU,
+		LensV: lensV,
+	}
+}
+
+//一つのsampleの色を求める関数、Renderから渡される
+type SampleFunc func(PixelSample) (Color, error)
+
+//一つのpixelの中にいくつsampleを取ってどう混ぜるかを決める
+//rngはpixelごとに作られるので、同じseedなら何度Renderしても同じ結果になる
+type Sampler interface {
+	SamplePixel(rng *Random, trace SampleFunc) (Color, error)
+}
+
+func averageColor(colors []Color) Color {
+	sum := Black
+	for _, c := range colors {
+		sum = sum.Add(c)
+	}
+
+	return sum.MulByScalar(1 / float64(len(colors)))
+}
+
+//今までと同じpixelの中心に一本だけ飛ばす
+type CenterSampler struct{}
+
+var _ Sampler = CenterSampler{}
+
+func (s CenterSampler) SamplePixel(rng *Random, trace SampleFunc) (Color, error) {
+	return trace(newPixelSample(0.5, 0.5, rng))
+}
+
+//pixelをN×Nに分けてそれぞれの中心に飛ばす
+type StratifiedSampler struct {
+	N int
+}
+
+var _ Sampler = StratifiedSampler{}
+
+func NewStratifiedSampler(n int) StratifiedSampler {
+	if n < 1 {
+		n = 1
+	}
+
+	return StratifiedSampler{
+		N: n,
+	}
+}
+
+func (s StratifiedSampler) SamplePixel(rng *Random, trace SampleFunc) (Color, error) {
+	return gridSamples(s.N, false, rng, trace)
+}
+
+//pixelをN×Nに分けて、それぞれのcellの中のrandomな位置に飛ばす
+type JitteredSampler struct {
+	N int
+}
+
+var _ Sampler = JitteredSampler{}
+
+func NewJitteredSampler(n int) JitteredSampler {
+	if n < 1 {
+		n = 1
+	}
+
+	return JitteredSampler{
+		N: n,
+	}
+}
+
+func (s JitteredSampler) SamplePixel(rng *Random, trace SampleFunc) (Color, error) {
+	return gridSamples(s.N, true, rng, trace)
+}
+
+//jitterがfalseのときはcellの中心
+func gridSamples(n int, jitter bool, rng *Random, trace SampleFunc) (Color, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	colors := make([]Color, 0, n*n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			ju, jv := 0.5, 0.5
+			if jitter && rng != nil {
+				ju, jv = rng.Float64(), rng.Float64()
+			}
+
+			c, err := trace(newPixelSample(
+				(float64(i)+ju)/float64(n),
+				(float64(j)+jv)/float64(n),
+				rng,
+			))
+			if err != nil {
+				return Color{}, err
+			}
+
+			colors = append(colors, c)
+		}
+	}
+
+	return averageColor(colors), nil
+}
+
+//pixelの四隅に飛ばし、色の差がThresholdを超えたところだけ四分割してさらに飛ばす
+//MaxDepth回まで分割する、MaxDepthが0なら四隅の平均
+type AdaptiveSampler struct {
+	MaxDepth  int
+	Threshold float64
+}
+
+var _ Sampler = AdaptiveSampler{}
+
+func NewAdaptiveSampler(maxDepth int, threshold float64) AdaptiveSampler {
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+
+	return AdaptiveSampler{
+		MaxDepth:  maxDepth,
+		Threshold: threshold,
+	}
+}
+
+//隣り合う領域で共有する角のsampleは一度だけ飛ばす
+type adaptiveCache struct {
+	trace   SampleFunc
+	rng     *Random
+	samples map[[2]float64]Color
+}
+
+func (a *adaptiveCache) sample(x, y float64) (Color, error) {
+	key := [2]float64{x, y}
+	if c, ok := a.samples[key]; ok {
+		return c, nil
+	}
+
+	c, err := a.trace(newPixelSample(x, y, a.rng))
+	if err != nil {
+		return Color{}, err
+	}
+
+	a.samples[key] = c
+	return c, nil
+}
+
+func colorDistance(c1, c2 Color) float64 {
+	return math.Max(
+		math.Abs(c1.Red-c2.Red),
+		math.Max(math.Abs(c1.Green-c2.Green), math.Abs(c1.Blue-c2.Blue)),
+	)
+}
+
+func (s AdaptiveSampler) SamplePixel(rng *Random, trace SampleFunc) (Color, error) {
+	cache := &adaptiveCache{
+		trace:   trace,
+		rng:     rng,
+		samples: make(map[[2]float64]Color),
+	}
+
+	return s.sampleRegion(cache, 0, 0, 1, 0)
+}
+
+func (s AdaptiveSampler) sampleRegion(cache *adaptiveCache, x, y, size float64, depth int) (Color, error) {
+	corners := make([]Color, 0, 4)
+	for _, p := range [][2]float64{{x, y}, {x + size, y}, {x, y + size}, {x + size, y + size}} {
+		c, err := cache.sample(p[0], p[1])
+		if err != nil {
+			return Color{}, err
+		}
+
+		corners = append(corners, c)
+	}
+
+	average := averageColor(corners)
+	if depth >= s.MaxDepth {
+		return average, nil
+	}
+
+	differ := false
+	for _, c := range corners {
+		if colorDistance(c, average) > s.Threshold {
+			differ = true
+			break
+		}
+	}
+
+	if !differ {
+		return average, nil
+	}
+
+	half := size / 2
+	quadrants := make([]Color, 0, 4)
+	for _, q := range [][2]float64{{x, y}, {x + half, y}, {x, y + half}, {x + half, y + half}} {
+		c, err := s.sampleRegion(cache, q[0], q[1], half, depth+1)
+		if err != nil {
+			return Color{}, err
+		}
+
+		quadrants = append(quadrants, c)
+	}
+
+	return averageColor(quadrants), nil
+}
@@ -0,0 +1,169 @@
+package scene
+
+import (
+	"errors"
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//sampleの位置を記録して、x座標が0.5より左なら黒、右なら白を返す
+func recordingTrace(samples *[]PixelSample) SampleFunc {
+	return func(s PixelSample) (Color, error) {
+		*samples = append(*samples, s)
+		if s.X < 0.5 {
+			return Black, nil
+		}
+
+		return White, nil
+	}
+}
+
+func uniformTrace(samples *[]PixelSample) SampleFunc {
+	return func(s PixelSample) (Color, error) {
+		*samples = append(*samples, s)
+		return White, nil
+	}
+}
+
+func Test_Center_Sampler(t *testing.T) {
+	var samples []PixelSample
+	c, err := CenterSampler{}.SamplePixel(NewRandom(0), recordingTrace(&samples))
+	require.Nil(t, err)
+
+	require.Equal(t, 1, len(samples))
+	require.Equal(t, 0.5, samples[0].X)
+	require.Equal(t, 0.5, samples[0].Y)
+	require.Equal(t, White, c)
+}
+
+func Test_Stratified_Sampler(t *testing.T) {
+	var samples []PixelSample
+	c, err := NewStratifiedSampler(2).SamplePixel(NewRandom(0), recordingTrace(&samples))
+	require.Nil(t, err)
+
+	require.Equal(t, 4, len(samples))
+	for i, ans := range [][2]float64{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}} {
+		require.Equal(t, ans[0], samples[i].X)
+		require.Equal(t, ans[1], samples[i].Y)
+	}
+	require.True(t, colorCompare(NewColor(0.5, 0.5, 0.5), c))
+}
+
+func Test_Jittered_Sampler(t *testing.T) {
+	var samples []PixelSample
+	_, err := NewJitteredSampler(3).SamplePixel(NewRandom(42), recordingTrace(&samples))
+	require.Nil(t, err)
+	require.Equal(t, 9, len(samples))
+
+	//それぞれのsampleは自分のcellの中にある
+	for i, s := range samples {
+		cx, cy := float64(i%3), float64(i/3)
+		require.True(t, cx/3 <= s.X && s.X < (cx+1)/3)
+		require.True(t, cy/3 <= s.Y && s.Y < (cy+1)/3)
+	}
+
+	//同じseedなら同じ位置
+	var again []PixelSample
+	_, err = NewJitteredSampler(3).SamplePixel(NewRandom(42), recordingTrace(&again))
+	require.Nil(t, err)
+	require.Equal(t, samples, again)
+}
+
+func Test_Adaptive_Sampler_Uniform_Pixel(t *testing.T) {
+	var samples []PixelSample
+	c, err := NewAdaptiveSampler(3, 0.1).SamplePixel(NewRandom(0), uniformTrace(&samples))
+	require.Nil(t, err)
+
+	//四隅が同じ色なら分割しない
+	require.Equal(t, 4, len(samples))
+	require.Equal(t, White, c)
+}
+
+func Test_Adaptive_Sampler_Refines_Edges(t *testing.T) {
+	var samples []PixelSample
+	c, err := NewAdaptiveSampler(2, 0.1).SamplePixel(NewRandom(0), recordingTrace(&samples))
+	require.Nil(t, err)
+
+	require.True(t, len(samples) > 4)
+	//角のsampleだけで見るので境界のsampleが白に寄る
+	require.True(t, colorCompare(NewColor(0.625, 0.625, 0.625), c), c)
+
+	//同じ位置には二度飛ばさない
+	seen := make(map[[2]float64]bool)
+	for _, s := range samples {
+		require.False(t, seen[[2]float64{s.X, s.Y}])
+		seen[[2]float64{s.X, s.Y}] = true
+	}
+
+	//MaxDepthが0なら四隅だけ
+	samples = nil
+	_, err = NewAdaptiveSampler(0, 0.1).SamplePixel(NewRandom(0), recordingTrace(&samples))
+	require.Nil(t, err)
+	require.Equal(t, 4, len(samples))
+}
+
+func Test_Sampler_Lens_Samples(t *testing.T) {
+	//lensの位置はsampleごとに変わり、rngがなければlensの中心
+	var samples []PixelSample
+	_, err := NewStratifiedSampler(2).SamplePixel(NewRandom(5), uniformTrace(&samples))
+	require.Nil(t, err)
+	require.NotEqual(t, samples[0].LensU, samples[1].LensU)
+
+	samples = nil
+	_, err = NewStratifiedSampler(2).SamplePixel(nil, uniformTrace(&samples))
+	require.Nil(t, err)
+	for _, s := range samples {
+		require.Equal(t, 0.5, s.LensU)
+		require.Equal(t, 0.5, s.LensV)
+	}
+}
+
+func Test_Sampler_Returns_Error(t *testing.T) {
+	traceErr := errors.New("trace failed")
+	trace := func(s PixelSample) (Color, error) {
+		return Color{}, traceErr
+	}
+
+	for _, sampler := range []Sampler{CenterSampler{}, NewStratifiedSampler(2), NewJitteredSampler(2), NewAdaptiveSampler(2, 0.1)} {
+		_, err := sampler.SamplePixel(NewRandom(0), trace)
+		require.Equal(t, traceErr, err)
+	}
+}
+
+func Test_Render_With_Samplers(t *testing.T) {
+	w := DefaultWorld()
+	camera := NewCamera(21, 21, math.Pi/2)
+	require.Nil(t, camera.SetTransform(ViewTransform(calc.NewPoint(0, 0, -5), calc.NewPoint(0, 0, 0), calc.NewVector(0, 1, 0))))
+
+	single, err := w.Render(camera)
+	require.Nil(t, err)
+
+	center, err := w.Render(camera, RenderSampler(CenterSampler{}))
+	require.Nil(t, err)
+	require.Equal(t, single.Pixels, center.Pixels)
+
+	for _, target := range []struct {
+		title   string
+		sampler Sampler
+	}{
+		{"stratified", NewStratifiedSampler(3)},
+		{"jittered", NewJitteredSampler(3)},
+		{"adaptive", NewAdaptiveSampler(2, 0.05)},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			c1, err := w.Render(camera, RenderSampler(target.sampler), RenderSeed(7), RenderWorkers(4), RenderTileSize(3))
+			require.Nil(t, err)
+
+			//tileの分け方やworker数が違っても同じseedなら同じ画像
+			c2, err := w.Render(camera, RenderSampler(target.sampler), RenderSeed(7), RenderWorkers(1))
+			require.Nil(t, err)
+			require.Equal(t, c1.Pixels, c2.Pixels)
+
+			//球の縁はsampleが混ざって中間の色になる
+			require.NotEqual(t, single.Pixels, c1.Pixels)
+		})
+	}
+}
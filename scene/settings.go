@@ -0,0 +1,36 @@
+package scene
+
+import "rayGo/util"
+
+//反射と屈折を何回まで追うかの既定値
+const DefaultMaxDepth = 5
+
+//一回のrenderで使う設定
+//Worldとは別に持ってColorAtなどに渡すので、同じWorldを違う設定で同時にrenderしても干渉しない
+//Render中は書き換えないこと
+type RenderSettings struct {
+	MaxReflectionDepth int
+	MaxRefractionDepth int
+	//OverPoint,UnderPointを面からずらす距離、小さすぎると自分の影が面に出る(shadow acne)
+	ShadowBias float64
+	//交差判定で0とみなす幅、rayのToleranceとして各shapeに渡る
+	//0にしたときだけutil.EPSILON(util.SetEpsilon)を使う
+	Epsilon float64
+	//rayの始点からこれより近い交点は無視する、0なら始点より前の交点は全て使う
+	MinHitDistance float64
+	//反射や屈折したrayも含めて、何にも当たらなかったrayの色
+	Background Background
+	Sampler    Sampler
+}
+
+func DefaultRenderSettings() *RenderSettings {
+	return &RenderSettings{
+		MaxReflectionDepth: DefaultMaxDepth,
+		MaxRefractionDepth: DefaultMaxDepth,
+		ShadowBias:         util.DefaultEpsilon,
+		Epsilon:            util.DefaultEpsilon,
+		MinHitDistance:     0,
+		Background:         NewSolidBackground(Black),
+		Sampler:            CenterSampler{},
+	}
+}
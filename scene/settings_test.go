@@ -0,0 +1,124 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"rayGo/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Default_Render_Settings(t *testing.T) {
+	settings := DefaultRenderSettings()
+	require.Equal(t, DefaultMaxDepth, settings.MaxReflectionDepth)
+	require.Equal(t, DefaultMaxDepth, settings.MaxRefractionDepth)
+	require.Equal(t, NewSolidBackground(Black), settings.Background)
+	require.Equal(t, CenterSampler{}, settings.Sampler)
+
+	//呼ぶたびに別のものを返すので書き換えても他に影響しない
+	settings.Background = NewSolidBackground(Red)
+	require.Equal(t, NewSolidBackground(Black), DefaultRenderSettings().Background)
+}
+
+func Test_Color_At_Miss_Uses_Background(t *testing.T) {
+	w := DefaultWorld()
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 1, 0))
+
+	settings := DefaultRenderSettings()
+	settings.Background = NewSolidBackground(NewColor(0.2, 0.3, 0.4))
+
+	c, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+	require.Equal(t, NewColor(0.2, 0.3, 0.4), c)
+}
+
+func Test_Min_Hit_Distance_Skips_Near_Hits(t *testing.T) {
+	w := DefaultWorld()
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+
+	settings := DefaultRenderSettings()
+	settings.Background = NewSolidBackground(Red)
+
+	//どちらのrayも球とはt=20より手前で交わる
+	settings.MinHitDistance = 20
+	c, err := w.ColorAt(r, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+	require.Equal(t, Red, c)
+
+	point := calc.NewPoint(10, -10, 10)
+	shadowed, err := w.IsShadowed(w.Lights[0].(Light).Position, point, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.True(t, shadowed)
+
+	shadowed, err = w.IsShadowed(w.Lights[0].(Light).Position, point, settings)
+	require.Nil(t, err)
+	require.False(t, shadowed)
+}
+
+func Test_Shadow_Bias_Moves_Over_And_Under_Point(t *testing.T) {
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+	shape := NewSphere(1)
+	i := CreateIntersection(4, shape)
+
+	comps, err := prepareComputations(i, r, Intersections{}, 0.25)
+	require.Nil(t, err)
+	require.True(t, math.Abs(comps.OverPoint[2]-(-1.25)) < 1e-9)
+	require.True(t, math.Abs(comps.UnderPoint[2]-(-0.75)) < 1e-9)
+}
+
+func Test_Separate_Reflection_And_Refraction_Depth(t *testing.T) {
+	w := DefaultWorld()
+
+	floor := NewPlane()
+	floor.SetTransform(calc.NewTranslation(0, -1, 0))
+	m := floor.GetMaterial()
+	m.Reflective = 0.5
+	floor.SetMaterial(m)
+	w.AddObjects(floor)
+
+	r := NewRay(calc.NewPoint(0, 0, -3), calc.NewVector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
+	xs, err := w.Intersect(r)
+	require.Nil(t, err)
+	comps, err := PrepareComputations(*GenerateHit(xs), r, xs)
+	require.Nil(t, err)
+
+	settings := DefaultRenderSettings()
+	settings.MaxReflectionDepth = 0
+
+	//屈折の深さが残っていても反射は追わない
+	color, err := w.ReflectedColor(comps, settings, settings.MaxReflectionDepth, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+	require.Equal(t, Black, color)
+
+	color, err = w.ReflectedColor(comps, settings, 1, settings.MaxRefractionDepth)
+	require.Nil(t, err)
+	require.NotEqual(t, Black, color)
+}
+
+func Test_Epsilon_Is_Used_For_Intersection(t *testing.T) {
+	//rayとほぼ平行なTriangle、detがEpsilonより小さいと交差しない
+	tri := NewTriangle(calc.NewPoint(0, 1, 0), calc.NewPoint(-1, 0, 0), calc.NewPoint(1, 0, 0.001))
+	w := NewWorld(NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1)), tri)
+	r := NewRay(calc.NewPoint(-2, 0.5, 0.0004), calc.NewVector(1, 0, 0))
+
+	loose := DefaultRenderSettings()
+	loose.Epsilon = 0.01
+	loose.Background = NewSolidBackground(Red)
+
+	strict := DefaultRenderSettings()
+	strict.Epsilon = 1e-9
+	strict.Background = NewSolidBackground(Red)
+
+	//全体のepsilonを変えてもsettingsの値で判定する
+	util.SetEpsilon(1)
+	defer util.SetEpsilon(util.DefaultEpsilon)
+
+	c, err := w.ColorAt(r, loose, DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+	require.Equal(t, Red, c)
+
+	c, err = w.ColorAt(r, strict, DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+	require.NotEqual(t, Red, c)
+}
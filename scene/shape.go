@@ -11,34 +11,61 @@ type Shape interface {
 	GetMaterial() *Material
 	SetMaterial(m *Material)
 	GetTransform() calc.Mat4x4
-	SetTransform(mat calc.Mat4x4)
+	SetTransform(mat calc.Mat4x4) error
+	GetInverse() calc.Mat4x4
+	GetInverseTranspose() calc.Mat4x4
 	GetParent() Shape //parentはそのshapeが属するGroupを表す
 	SetParent(s Shape)
 	WorldToObject(point calc.Tuple4) (calc.Tuple4, error)
 	NormalToWorld(normal_vec calc.Tuple4) (calc.Tuple4, error)
 	IsInclude(s Shape) bool
+	Bounds() BoundingBox //object空間でのbounding box
 }
 
+//transformはSetTransformでしか変えられない、逆行列はそのときに一度だけ計算する
 type BaseShape struct {
-	Transform calc.Mat4x4
-	Material  *Material
-	Parent    Shape
+	transform        calc.Mat4x4
+	Material         *Material
+	Parent           Shape
+	inverse          calc.Mat4x4
+	inverseTranspose calc.Mat4x4
 }
 
 func NewBaseShape() *BaseShape {
 	return &BaseShape{
-		Transform: calc.Ident4x4,
-		Material:  DefaultMaterial(),
-		Parent:    nil,
+		transform:        calc.Ident4x4,
+		Material:         DefaultMaterial(),
+		Parent:           nil,
+		inverse:          calc.Ident4x4,
+		inverseTranspose: calc.Ident4x4,
 	}
 }
 
 func (b *BaseShape) GetTransform() calc.Mat4x4 {
-	return b.Transform
+	return b.transform
 }
 
-func (b *BaseShape) SetTransform(mat calc.Mat4x4) {
-	b.Transform = mat
+//逆行列を持たないtransformはここでerrorを返し、元のtransformのままにする
+func (b *BaseShape) SetTransform(mat calc.Mat4x4) error {
+	inv, err := mat.Inverse()
+	if err != nil {
+		return err
+	}
+
+	b.transform = mat
+	b.inverse = inv
+	b.inverseTranspose = inv.Transpose()
+	invalidateParentBounds(b.Parent)
+
+	return nil
+}
+
+func (b *BaseShape) GetInverse() calc.Mat4x4 {
+	return b.inverse
+}
+
+func (b *BaseShape) GetInverseTranspose() calc.Mat4x4 {
+	return b.inverseTranspose
 }
 
 func (b *BaseShape) GetParent() Shape {
@@ -53,16 +80,11 @@ type CalcLocalNormal func(localPoint calc.Tuple4, hit Intersection) calc.Tuple4
 
 //各shapeごとにnormalだったりintersectを求める方法が違うのでそこはfuncで引数経由で渡せばいい
 func (base *BaseShape) ShapeNormalAt(worldPoint calc.Tuple4, hit Intersection, calcLocalNormal CalcLocalNormal) (calc.Tuple4, error) {
-	invTrans, err := base.GetTransform().Inverse()
-	if err != nil {
-		return calc.Tuple4{}, err
-	}
-
-	localPoint := invTrans.MulByTuple(worldPoint)
+	localPoint := base.inverse.MulByTuple(worldPoint)
 	localNormal := calcLocalNormal(localPoint, hit)
 
 	//objectNormal -> worldNormalでなぜinverse->TransposeがいるかはPDFに記載
-	worldNormal := invTrans.Transpose().MulByTuple(localNormal)
+	worldNormal := base.inverseTranspose.MulByTuple(localNormal)
 	worldNormal[3] = 0
 
 	return worldNormal.Normalize(), nil
@@ -76,16 +98,12 @@ func (base *BaseShape) ShapeNormalAt(worldPoint calc.Tuple4, hit Intersection, c
 }
 
 func (base *BaseShape) NormalToWorld(normal_vec calc.Tuple4) (calc.Tuple4, error) {
-	invTrans, err := base.GetTransform().Inverse()
-	if err != nil {
-		return calc.Tuple4{}, err
-	}
-
-	worldNormal := invTrans.Transpose().MulByTuple(normal_vec)
+	worldNormal := base.inverseTranspose.MulByTuple(normal_vec)
 	worldNormal[3] = 0
 	worldNormal = worldNormal.Normalize()
 
 	if base.GetParent() != nil {
+		var err error
 		worldNormal, err = base.GetParent().NormalToWorld(worldNormal)
 		if err != nil {
 			return calc.Tuple4{}, err
@@ -99,12 +117,7 @@ func (base *BaseShape) NormalToWorld(normal_vec calc.Tuple4) (calc.Tuple4, error
 type CalcLocalIntersect func(localRay Ray) (Intersections, error)
 
 func (base *BaseShape) ShapeIntersect(r Ray, localIntersect CalcLocalIntersect) (Intersections, error) {
-	invTrans, err := base.GetTransform().Inverse()
-	if err != nil {
-		return Intersections{}, err
-	}
-
-	r = r.Transform(invTrans)
+	r = r.Transform(base.inverse)
 
 	return localIntersect(r)
 }
@@ -123,16 +136,10 @@ func (base *BaseShape) WorldToObject(p calc.Tuple4) (calc.Tuple4, error) {
 		point = inversedPoint
 	}
 
-	transInv, err := base.GetTransform().Inverse()
-
-	if err != nil {
-		return calc.Tuple4{}, err
-	}
-
-	return transInv.MulByTuple(point), nil
+	return base.inverse.MulByTuple(point), nil
 }
 
-//U,VはSmoothTriangleでのみ使用、それ以外のShapeでは0,0のdefault値のまま
+//U,VはTriangle,SmoothTriangleの重心座標、それ以外のShapeでは0,0のdefault値のまま
 type Intersection struct {
 	Time   float64
 	Object Shape
@@ -160,13 +167,18 @@ func AggregateIntersection(sections ...*Intersection) Intersections {
 
 //hitはintersectionsの中で最初の正のtimeをもつintersection
 func GenerateHit(intersections Intersections) *Intersection {
+	return generateHitAfter(intersections, 0)
+}
+
+//tMinより手前の交点はrayの始点と同じ面とみなして飛ばす
+func generateHitAfter(intersections Intersections, tMin float64) *Intersection {
 	for _, intersection := range intersections.Intersections {
-		if intersection.Time >= 0 {
+		if intersection.Time >= tMin {
 			return intersection
 		}
 	}
 
-	//正のtimeのintersectionがなかったらnil
+	//tMin以上のtimeのintersectionがなかったらnil
 	return nil
 }
 
@@ -1,9 +1,6 @@
 package scene
 
-import (
-	"rayGo/calc"
-	"rayGo/util"
-)
+import "rayGo/calc"
 
 type SmoothTriangle struct {
 	*BaseShape
@@ -16,6 +13,11 @@ type SmoothTriangle struct {
 	E1        calc.Tuple4
 	E2        calc.Tuple4
 	NormalVec calc.Tuple4
+	T1        TexCoord
+	T2        TexCoord
+	T3        TexCoord
+	//T1~T3が設定されているか
+	HasTexCoord bool
 }
 
 func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 calc.Tuple4) SmoothTriangle {
@@ -33,10 +35,24 @@ func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 calc.Tuple4) SmoothTriangle {
 		e1,
 		e2,
 		normal_vec,
+		TexCoord{},
+		TexCoord{},
+		TexCoord{},
+		false,
 	}
 }
 
+//頂点ごとにtexture座標を持つSmoothTriangle
+func NewTexturedSmoothTriangle(p1, p2, p3, n1, n2, n3 calc.Tuple4, t1, t2, t3 TexCoord) SmoothTriangle {
+	tri := NewSmoothTriangle(p1, p2, p3, n1, n2, n3)
+	tri.T1, tri.T2, tri.T3 = t1, t2, t3
+	tri.HasTexCoord = true
+
+	return tri
+}
+
 var _ Shape = SmoothTriangle{}
+var _ TexturedShape = SmoothTriangle{}
 
 func (tri SmoothTriangle) calcLocalNormal(localPoint calc.Tuple4, hit Intersection) calc.Tuple4 {
 	return calc.AddTuple(calc.MulTupleByScalar(hit.U, tri.N2), calc.AddTuple(calc.MulTupleByScalar(hit.V, tri.N3),
@@ -53,7 +69,7 @@ func (tri SmoothTriangle) calcLocalIntersect(r Ray) (Intersections, error) {
 
 	det := calc.DotTuple(tri.E1, dir_cross_e2)
 
-	if util.IsNearlyEqualZero(det) {
+	if r.isNearlyZero(det) {
 		return Intersections{}, nil
 	}
 
@@ -86,6 +102,14 @@ func (tri SmoothTriangle) Intersect(r Ray) (Intersections, error) {
 	return tri.ShapeIntersect(r, tri.calcLocalIntersect)
 }
 
+func (tri SmoothTriangle) TexCoordAt(hit Intersection) (TexCoord, bool) {
+	if !tri.HasTexCoord {
+		return TexCoord{}, false
+	}
+
+	return interpolateTexCoord(hit, tri.T1, tri.T2, tri.T3), true
+}
+
 func (tri SmoothTriangle) GetMaterial() *Material {
 	return tri.Material
 }
@@ -97,3 +121,7 @@ func (tri SmoothTriangle) SetMaterial(m *Material) {
 func (tri SmoothTriangle) IsInclude(s Shape) bool {
 	return tri == s
 }
+
+func (tri SmoothTriangle) Bounds() BoundingBox {
+	return EmptyBoundingBox().AddPoint(tri.P1).AddPoint(tri.P2).AddPoint(tri.P3)
+}
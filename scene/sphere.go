@@ -85,6 +85,10 @@ func (s Sphere) IsInclude(s2 Shape) bool {
 	return s == s2
 }
 
+func (s Sphere) Bounds() BoundingBox {
+	return NewBoundingBox(calc.NewPoint(-1, -1, -1), calc.NewPoint(1, 1, 1))
+}
+
 // //sphere自体を動かす代わりにNormalを動かして計算
 // func (s Sphere) NormalAt(worldPoint calc.Tuple4) (calc.Tuple4, error) {
 // 	invTrans, err := s.GetTransform().Inverse()
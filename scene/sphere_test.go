@@ -18,6 +18,33 @@ func Test_Sphere_Transformation(t *testing.T) {
 
 }
 
+func Test_SetTransform_Caches_Inverse(t *testing.T) {
+	s := NewSphere(1)
+	require.Equal(t, calc.Mat4x4(calc.Ident4x4), s.GetInverse())
+
+	trans := calc.NewTranslation(2, 3, 4).MulByMat4x4(calc.NewScale(1, 2, 4))
+	require.Nil(t, s.SetTransform(trans))
+
+	inv, err := trans.Inverse()
+	require.Nil(t, err)
+	require.Equal(t, inv, s.GetInverse())
+	require.Equal(t, inv.Transpose(), s.GetInverseTranspose())
+}
+
+func Test_SetTransform_Rejects_Non_Invertible_Matrix(t *testing.T) {
+	s := NewSphere(1)
+	trans := calc.NewTranslation(2, 3, 4)
+	require.Nil(t, s.SetTransform(trans))
+
+	//errorは設定したときに一度だけ返り、元のtransformのまま交差判定できる
+	require.NotNil(t, s.SetTransform(calc.NewScale(0, 1, 1)))
+	require.Equal(t, trans, s.GetTransform())
+
+	xs, err := s.Intersect(NewRay(calc.NewPoint(2, 3, -5), calc.NewVector(0, 0, 1)))
+	require.Nil(t, err)
+	require.Equal(t, 2, xs.Count)
+}
+
 func Test_Normal(t *testing.T) {
 	s := NewSphere(1)
 
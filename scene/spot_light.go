@@ -0,0 +1,84 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//PositionからDirectionの向きに円錐状に照らす光源
+//InnerAngleの内側は全て照らし、OuterAngleに向かってなめらかに暗くなる(角度は円錐の軸からのradian)
+type SpotLight struct {
+	Position    calc.Tuple4
+	Direction   calc.Tuple4
+	InnerAngle  float64
+	OuterAngle  float64
+	Intensity   Color
+	Attenuation Attenuation
+}
+
+var _ LightSource = SpotLight{}
+
+func NewSpotLight(position, direction calc.Tuple4, innerAngle, outerAngle float64, intensity Color, options ...LightOption) SpotLight {
+	opts := newLightOptions(options...)
+
+	return SpotLight{
+		Position:    position,
+		Direction:   direction.Normalize(),
+		InnerAngle:  innerAngle,
+		OuterAngle:  outerAngle,
+		Intensity:   intensity,
+		Attenuation: opts.Attenuation,
+	}
+}
+
+//円錐の中で0~1、smoothstepで内側と外側の境目をぼかす
+func (l SpotLight) Falloff(point calc.Tuple4) float64 {
+	cos := calc.DotTuple(calc.SubTuple(point, l.Position).Normalize(), l.Direction)
+	cosInner := math.Cos(l.InnerAngle)
+	cosOuter := math.Cos(l.OuterAngle)
+
+	if cos >= cosInner {
+		return 1
+	}
+
+	//InnerAngleとOuterAngleが同じときは境目がくっきりする
+	if cos <= cosOuter || cosInner <= cosOuter {
+		return 0
+	}
+
+	t := (cos - cosOuter) / (cosInner - cosOuter)
+	return t * t * (3 - 2*t)
+}
+
+func (l SpotLight) IntensityAt(point calc.Tuple4, w *World, settings *RenderSettings) (float64, error) {
+	falloff := l.Falloff(point)
+	if falloff == 0 {
+		return 0, nil
+	}
+
+	in_shadow, err := w.IsShadowed(l.Position, point, settings)
+	if err != nil {
+		return 0, err
+	}
+
+	if in_shadow {
+		return 0, nil
+	}
+
+	return falloff, nil
+}
+
+func (l SpotLight) Lighting(m *Material, position, eye_vec, normal_vec calc.Tuple4, intensity float64, shape Shape) (Color, error) {
+	return lighting(l, m, position, eye_vec, normal_vec, intensity, shape)
+}
+
+func (l SpotLight) DirectLighting(m *Material, materialColor Color, position, eye_vec, normal_vec calc.Tuple4, intensity float64) Color {
+	v := calc.SubTuple(l.Position, position)
+	attenuation := l.Attenuation.Factor(v.Magnitude())
+
+	return phongDirectLighting(m, materialColor, l.Intensity, v.Normalize(), eye_vec, normal_vec, intensity*attenuation)
+}
+
+func (l SpotLight) GetIntensity() Color {
+	return l.Intensity
+}
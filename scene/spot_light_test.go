@@ -0,0 +1,133 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SpotLight_Falloff(t *testing.T) {
+	light := NewSpotLight(calc.NewPoint(0, 10, 0), calc.NewVector(0, -2, 0), math.Pi/8, math.Pi/4, NewColor(1, 1, 1))
+
+	require.Equal(t, calc.NewVector(0, -1, 0), light.Direction)
+
+	for _, target := range []struct {
+		title string
+		point calc.Tuple4
+		ans   float64
+	}{
+		{"on the axis", calc.NewPoint(0, 0, 0), 1},
+		{"inside the inner cone", calc.NewPoint(10*math.Tan(math.Pi/10), 0, 0), 1},
+		{"outside the outer cone", calc.NewPoint(10*math.Tan(math.Pi/3), 0, 0), 0},
+		{"behind the light", calc.NewPoint(0, 20, 0), 0},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			require.True(t, math.Abs(target.ans-light.Falloff(target.point)) < 0.00001)
+		})
+	}
+
+	//内側から外側へ向かって単調に暗くなる
+	prev := 1.0
+	for angle := math.Pi / 8; angle <= math.Pi/4; angle += math.Pi / 80 {
+		f := light.Falloff(calc.NewPoint(10*math.Tan(angle), 0, 0))
+		require.True(t, f <= prev)
+		prev = f
+	}
+
+	mid := light.Falloff(calc.NewPoint(10*math.Tan(3*math.Pi/16), 0, 0))
+	require.True(t, 0 < mid && mid < 1)
+}
+
+func Test_SpotLight_IntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	light := NewSpotLight(calc.NewPoint(0, 0, -10), calc.NewVector(0, 0, 1), math.Pi/8, math.Pi/6, NewColor(1, 1, 1))
+
+	//球の手前は照らされ、球の後ろは影
+	front, err := light.IntensityAt(calc.NewPoint(0, 0, -1.0001), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.Equal(t, 1.0, front)
+
+	behind, err := light.IntensityAt(calc.NewPoint(0, 0, 1.0001), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.Equal(t, 0.0, behind)
+
+	outside, err := light.IntensityAt(calc.NewPoint(0, 8, 0), w, DefaultRenderSettings())
+	require.Nil(t, err)
+	require.Equal(t, 0.0, outside)
+}
+
+func Test_SpotLight_Lighting_Matches_Point_Light_Inside_Cone(t *testing.T) {
+	m := DefaultMaterial()
+	s := NewSphere(1)
+	pos := calc.NewPoint(0, 0, 0)
+	eye_vec := calc.NewVector(0, 0, -1)
+	normal_vec := calc.NewVector(0, 0, -1)
+
+	point := NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	spot := NewSpotLight(calc.NewPoint(0, 0, -10), calc.NewVector(0, 0, 1), math.Pi/8, math.Pi/6, NewColor(1, 1, 1))
+
+	c1, err := point.Lighting(m, pos, eye_vec, normal_vec, 1.0, s)
+	require.Nil(t, err)
+
+	c2, err := spot.Lighting(m, pos, eye_vec, normal_vec, 1.0, s)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(NewColor(1.9, 1.9, 1.9), c2))
+	require.True(t, colorCompare(c1, c2))
+}
+
+func Test_DirectionalLight_IntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	light := NewDirectionalLight(calc.NewVector(0, -1, 0), NewColor(1, 1, 1))
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   float64
+	}{
+		{calc.NewPoint(0, 1.0001, 0), 1},
+		{calc.NewPoint(0, -1.0001, 0), 0},
+		//光源までの距離に関係なく、どれだけ下でも真上の球に遮られる
+		{calc.NewPoint(0, -1000, 0), 0},
+		{calc.NewPoint(2, -1000, 0), 1},
+	} {
+		intensity, err := light.IntensityAt(target.point, w, DefaultRenderSettings())
+		require.Nil(t, err)
+		require.Equal(t, target.ans, intensity)
+	}
+}
+
+func Test_DirectionalLight_Lighting(t *testing.T) {
+	m := DefaultMaterial()
+	s := NewSphere(1)
+	pos := calc.NewPoint(0, 0, 0)
+	eye_vec := calc.NewVector(0, 0, -1)
+	normal_vec := calc.NewVector(0, 0, -1)
+
+	//光源の位置がなくても向きだけで同じ結果になる
+	light := NewDirectionalLight(calc.NewVector(0, 0, 1), NewColor(1, 1, 1))
+
+	for _, p := range []calc.Tuple4{pos, calc.NewPoint(100, -50, 3)} {
+		res, err := light.Lighting(m, p, eye_vec, normal_vec, 1.0, s)
+		require.Nil(t, err)
+		require.True(t, colorCompare(NewColor(1.9, 1.9, 1.9), res))
+	}
+}
+
+func Test_World_With_Mixed_Lights(t *testing.T) {
+	w := DefaultWorld()
+	w.AddLights(
+		NewDirectionalLight(calc.NewVector(1, -1, 1), NewColor(0.5, 0.5, 0.5)),
+		NewSpotLight(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1), math.Pi/8, math.Pi/6, NewColor(0.5, 0.5, 0.5)),
+	)
+
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+	c, err := w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	single, err := DefaultWorld().ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	require.True(t, c.Red > single.Red)
+}
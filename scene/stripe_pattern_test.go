@@ -96,3 +96,10 @@ func Test_Stripe_With_Both_Object_And_Pattern_Transformation(t *testing.T) {
 	require.True(t, colorCompare(Black, c))
 
 }
+
+func Test_Pattern_SetTransform_Rejects_Non_Invertible_Matrix(t *testing.T) {
+	pattern := NewStripePattern(White, Black)
+	require.NotNil(t, pattern.SetTransform(calc.NewScale(0, 0, 0)))
+	require.Equal(t, calc.Mat4x4(calc.Ident4x4), pattern.GetTransform())
+	require.Equal(t, calc.Mat4x4(calc.Ident4x4), pattern.GetInverse())
+}
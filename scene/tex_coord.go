@@ -0,0 +1,56 @@
+package scene
+
+//objのvtのように頂点ごとに付けるtexture座標
+type TexCoord struct {
+	U float64
+	V float64
+}
+
+func NewTexCoord(u, v float64) TexCoord {
+	return TexCoord{
+		U: u,
+		V: v,
+	}
+}
+
+//頂点にtexture座標を持つshape、Triangle,SmoothTriangleが実装する
+//持っていないときはfalseを返すのでTextureMapPatternはUVMappingにfallbackする
+type TexturedShape interface {
+	TexCoordAt(hit Intersection) (TexCoord, bool)
+}
+
+//hitのU,Vは(P2,P3)に対する重心座標なのでそのまま頂点のtexture座標を補間する
+func interpolateTexCoord(hit Intersection, t1, t2, t3 TexCoord) TexCoord {
+	w := 1 - hit.U - hit.V
+
+	return TexCoord{
+		U: w*t1.U + hit.U*t2.U + hit.V*t3.U,
+		V: w*t1.V + hit.U*t2.V + hit.V*t3.V,
+	}
+}
+
+//Lightingにはshapeしか渡らないので、交点で補間したtexture座標をshapeに付けて渡す
+type texCoordHitShape struct {
+	Shape
+	texCoord TexCoord
+}
+
+//PatternAtShapeに渡されたshapeが交点のtexture座標を持っていれば返す
+func hitTexCoord(shape Shape) (TexCoord, bool) {
+	s, ok := shape.(texCoordHitShape)
+	if !ok {
+		return TexCoord{}, false
+	}
+
+	return s.texCoord, true
+}
+
+//hitがtexture座標を持つshapeに当たっていれば補間した座標を返す
+func hitTexCoordOf(hit Intersection) (TexCoord, bool) {
+	textured, ok := hit.Object.(TexturedShape)
+	if !ok {
+		return TexCoord{}, false
+	}
+
+	return textured.TexCoordAt(hit)
+}
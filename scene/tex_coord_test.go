@@ -0,0 +1,77 @@
+package scene
+
+import (
+	"rayGo/calc"
+	"rayGo/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func texturedTestTriangle() Triangle {
+	return NewTexturedTriangle(
+		calc.NewPoint(0, 1, 0), calc.NewPoint(-1, 0, 0), calc.NewPoint(1, 0, 0),
+		NewTexCoord(0.5, 1), NewTexCoord(0, 0), NewTexCoord(1, 0),
+	)
+}
+
+func Test_Triangle_Interpolates_Texture_Coordinates(t *testing.T) {
+	for _, target := range []struct {
+		point    calc.Tuple4
+		expected TexCoord
+	}{
+		{calc.NewPoint(0, 0.999, -2), NewTexCoord(0.5, 0.999)},
+		{calc.NewPoint(-0.999, 0.0005, -2), NewTexCoord(0.0005, 0.0005)},
+		{calc.NewPoint(0, 0.5, -2), NewTexCoord(0.5, 0.5)},
+		{calc.NewPoint(-0.2, 0.3, -2), NewTexCoord(0.4, 0.3)},
+	} {
+		tri := texturedTestTriangle()
+		xs, err := tri.Intersect(NewRay(target.point, calc.NewVector(0, 0, 1)))
+		require.Nil(t, err)
+		require.Equal(t, 1, xs.Count)
+
+		uv, ok := tri.TexCoordAt(*xs.Intersections[0])
+		require.True(t, ok)
+		require.True(t, util.FloatEqual(target.expected.U, uv.U), "%v", uv)
+		require.True(t, util.FloatEqual(target.expected.V, uv.V), "%v", uv)
+	}
+}
+
+func Test_Triangle_Without_Texture_Coordinates(t *testing.T) {
+	tri := NewTriangle(calc.NewPoint(0, 1, 0), calc.NewPoint(-1, 0, 0), calc.NewPoint(1, 0, 0))
+	_, ok := tri.TexCoordAt(Intersection{1, tri, 0.25, 0.25})
+	require.False(t, ok)
+
+	smooth := NewTexturedSmoothTriangle(
+		calc.NewPoint(0, 1, 0), calc.NewPoint(-1, 0, 0), calc.NewPoint(1, 0, 0),
+		calc.NewVector(0, 1, 0), calc.NewVector(-1, 0, 0), calc.NewVector(1, 0, 0),
+		NewTexCoord(0, 0), NewTexCoord(1, 0), NewTexCoord(0, 1),
+	)
+	uv, ok := smooth.TexCoordAt(Intersection{1, smooth, 0.25, 0.5})
+	require.True(t, ok)
+	require.Equal(t, NewTexCoord(0.25, 0.5), uv)
+}
+
+func Test_Texture_Map_Pattern_Uses_Mesh_Texture_Coordinates(t *testing.T) {
+	tri := texturedTestTriangle()
+	tri.Material.Ambient = 1
+	tri.Material.Diffuse = 0
+	tri.Material.Specular = 0
+	//左右で色が分かれるtexture、UVMappingを使うとどちらもzが0なので同じ色になる
+	tri.Material.SetPattern(NewTextureMapPattern(NewUVCheckers(2, 1, White, Black), PlanarMap))
+
+	w := NewWorld(NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1)), tri)
+	settings := DefaultRenderSettings()
+
+	for _, target := range []struct {
+		point    calc.Tuple4
+		expected Color
+	}{
+		{calc.NewPoint(-0.5, 0.25, -2), White},
+		{calc.NewPoint(0.5, 0.25, -2), Black},
+	} {
+		c, err := w.ColorAt(NewRay(target.point, calc.NewVector(0, 0, 1)), settings, DefaultMaxDepth, DefaultMaxDepth)
+		require.Nil(t, err)
+		require.Equal(t, target.expected, c)
+	}
+}
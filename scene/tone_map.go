@@ -0,0 +1,87 @@
+package scene
+
+import (
+	"math"
+)
+
+//linearなHDRの色を表示できる0~1の範囲に収める
+type ToneMapper interface {
+	Map(c Color) Color
+}
+
+func mapChannels(c Color, fn func(float64) float64) Color {
+	return NewColor(fn(c.Red), fn(c.Green), fn(c.Blue))
+}
+
+//露出をstops段だけ変える、2^stops倍するだけで圧縮はしない
+type ExposureToneMap struct {
+	Stops float64
+}
+
+var _ ToneMapper = ExposureToneMap{}
+
+func NewExposureToneMap(stops float64) ExposureToneMap {
+	return ExposureToneMap{
+		Stops: stops,
+	}
+}
+
+func (e ExposureToneMap) Map(c Color) Color {
+	return c.MulByScalar(math.Pow(2, e.Stops))
+}
+
+//c/(1+c)、WhitePointを指定するとその明るさがちょうど1になる
+type ReinhardToneMap struct {
+	WhitePoint float64
+}
+
+var _ ToneMapper = ReinhardToneMap{}
+
+func NewReinhardToneMap(whitePoint float64) ReinhardToneMap {
+	return ReinhardToneMap{
+		WhitePoint: whitePoint,
+	}
+}
+
+func (r ReinhardToneMap) Map(c Color) Color {
+	return mapChannels(c, func(x float64) float64 {
+		if x <= 0 {
+			return 0
+		}
+
+		if r.WhitePoint <= 0 {
+			return x / (1 + x)
+		}
+
+		return x * (1 + x/(r.WhitePoint*r.WhitePoint)) / (1 + x)
+	})
+}
+
+//ACES filmic curveのNarkowiczによる近似
+type ACESToneMap struct{}
+
+var _ ToneMapper = ACESToneMap{}
+
+func (a ACESToneMap) Map(c Color) Color {
+	return mapChannels(c, func(x float64) float64 {
+		if x <= 0 {
+			return 0
+		}
+
+		mapped := (x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14)
+		return math.Min(math.Max(mapped, 0), 1)
+	})
+}
+
+//前から順に適用する、露出を変えてからReinhardをかけるときなど
+type ToneMapChain []ToneMapper
+
+var _ ToneMapper = ToneMapChain{}
+
+func (t ToneMapChain) Map(c Color) Color {
+	for _, m := range t {
+		c = m.Map(c)
+	}
+
+	return c
+}
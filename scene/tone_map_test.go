@@ -0,0 +1,59 @@
+package scene
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Exposure_Tone_Map(t *testing.T) {
+	require.Equal(t, NewColor(0.5, 1, 2), NewExposureToneMap(1).Map(NewColor(0.25, 0.5, 1)))
+	require.Equal(t, NewColor(0.125, 0.25, 0.5), NewExposureToneMap(-1).Map(NewColor(0.25, 0.5, 1)))
+}
+
+func Test_Reinhard_Tone_Map(t *testing.T) {
+	require.True(t, colorCompare(NewColor(0, 0.5, 0.8), ReinhardToneMap{}.Map(NewColor(-1, 1, 4))))
+
+	//WhitePointの明るさがちょうど1になる
+	white := NewReinhardToneMap(4)
+	require.True(t, colorCompare(NewColor(1, 1, 1), white.Map(NewColor(4, 4, 4))))
+	require.True(t, colorCompare(NewColor(0.53125, 0.53125, 0.53125), white.Map(NewColor(1, 1, 1))))
+}
+
+func Test_ACES_Tone_Map(t *testing.T) {
+	a := ACESToneMap{}
+
+	require.Equal(t, Black, a.Map(Black))
+	require.Equal(t, Black, a.Map(NewColor(-1, -1, -1)))
+	require.True(t, colorCompare(NewColor(0.80380, 0.80380, 0.80380), a.Map(White)))
+
+	//明るくなるほど1に近づき、1を超えない
+	prev := 0.0
+	for _, x := range []float64{0.1, 0.5, 1, 2, 8, 100} {
+		c := a.Map(NewColor(x, x, x))
+		require.True(t, prev <= c.Red && c.Red <= 1)
+		prev = c.Red
+	}
+}
+
+func Test_Tone_Map_Chain(t *testing.T) {
+	chain := ToneMapChain{NewExposureToneMap(2), ReinhardToneMap{}}
+	require.True(t, colorCompare(NewColor(0.5, 0.8, 0), chain.Map(NewColor(0.25, 1, 0))))
+}
+
+func Test_Export_With_Tone_Map(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.WritePixel(0, 0, NewColor(3, 1, 0))
+	c.WritePixel(1, 0, NewColor(0.5, 0.5, 0.5))
+
+	var buf bytes.Buffer
+	require.Nil(t, c.WritePPM(&buf, ImageToneMap(ReinhardToneMap{})))
+	require.Equal(t, "P3\n2 1\n255\n192 128 0 85 85 85\n", buf.String())
+
+	//canvas自体はlinearなまま
+	require.Equal(t, NewColor(3, 1, 0), c.Pixels[0][0])
+
+	r, _, _, _ := c.Image(ImageToneMap(ReinhardToneMap{})).At(0, 0).RGBA()
+	require.Equal(t, uint32(192), r>>8)
+}
@@ -0,0 +1,81 @@
+package scene
+
+import (
+	"math"
+)
+
+//linearな値(0~1)と画像に保存する値(0~1)の変換
+//書き出すときはEncode、textureとして読み込むときはDecode
+type Transfer interface {
+	Encode(x float64) float64
+	Decode(x float64) float64
+}
+
+//変換しない
+type LinearTransfer struct{}
+
+var _ Transfer = LinearTransfer{}
+
+func (l LinearTransfer) Encode(x float64) float64 {
+	return x
+}
+
+func (l LinearTransfer) Decode(x float64) float64 {
+	return x
+}
+
+//x^(1/Gamma)で書き出す、表示するときにx^Gammaされて元の明るさに戻る
+type GammaTransfer struct {
+	Gamma float64
+}
+
+var _ Transfer = GammaTransfer{}
+
+func NewGammaTransfer(gamma float64) GammaTransfer {
+	return GammaTransfer{
+		Gamma: gamma,
+	}
+}
+
+func (g GammaTransfer) Encode(x float64) float64 {
+	return math.Pow(x, 1/g.Gamma)
+}
+
+func (g GammaTransfer) Decode(x float64) float64 {
+	return math.Pow(x, g.Gamma)
+}
+
+//IEC 61966-2-1のsRGBの変換式、暗いところだけ直線になっている
+type SRGBTransfer struct{}
+
+var _ Transfer = SRGBTransfer{}
+
+func (s SRGBTransfer) Encode(x float64) float64 {
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+func (s SRGBTransfer) Decode(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+//NaNはmath.Min,math.Maxをそのまま通り抜けてintにしたときの値が決まらないので0にする
+func clamp01(x float64) float64 {
+	if math.IsNaN(x) {
+		return 0
+	}
+
+	return math.Min(math.Max(x, 0), 1)
+}
+
+//transferでencodeしてから0~maxValueの一番近い整数にする
+func encodeColorValue(x float64, maxValue int, transfer Transfer) int {
+	return int(math.Round(transfer.Encode(clamp01(x)) * float64(maxValue)))
+}
@@ -0,0 +1,107 @@
+package scene
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transfer_Encode(t *testing.T) {
+	for _, target := range []struct {
+		title    string
+		transfer Transfer
+		x        float64
+		ans      float64
+	}{
+		{"linear", LinearTransfer{}, 0.5, 0.5},
+		{"gamma 2.2", NewGammaTransfer(2.2), 0.5, 0.72974},
+		{"srgb midtone", SRGBTransfer{}, 0.5, 0.73536},
+		{"srgb toe is linear", SRGBTransfer{}, 0.002, 0.02584},
+		{"srgb white", SRGBTransfer{}, 1, 1},
+		{"srgb black", SRGBTransfer{}, 0, 0},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			require.True(t, math.Abs(target.ans-target.transfer.Encode(target.x)) < 0.00001, target.transfer.Encode(target.x))
+		})
+	}
+}
+
+func Test_Transfer_Decode_Inverts_Encode(t *testing.T) {
+	for _, transfer := range []Transfer{LinearTransfer{}, NewGammaTransfer(2.2), SRGBTransfer{}} {
+		for _, x := range []float64{0, 0.001, 0.0031308, 0.04, 0.2, 0.5, 0.9, 1} {
+			require.True(t, math.Abs(x-transfer.Decode(transfer.Encode(x))) < 0.00001)
+		}
+	}
+}
+
+func Test_Export_With_Transfer(t *testing.T) {
+	c := NewCanvas(3, 1)
+	c.WritePixel(0, 0, NewColor(0.5, 0.2, 0))
+	c.WritePixel(1, 0, NewColor(1.5, -0.5, 0.0005))
+	c.WritePixel(2, 0, NewColor(0.4, 0.4, 0.4))
+
+	for _, target := range []struct {
+		title    string
+		transfer Transfer
+		ans      string
+	}{
+		//指定しないときは今までどおり切り上げ
+		{"default", nil, "128 51 0 255 0 1 102 102 102"},
+		//linearでも四捨五入になる
+		{"linear", LinearTransfer{}, "128 51 0 255 0 0 102 102 102"},
+		{"gamma 2.2", NewGammaTransfer(2.2), "186 123 0 255 0 8 168 168 168"},
+		{"srgb", SRGBTransfer{}, "188 124 0 255 0 2 170 170 170"},
+	} {
+		t.Run(target.title, func(t *testing.T) {
+			var buf bytes.Buffer
+			var options []ImageOption
+			if target.transfer != nil {
+				options = append(options, ImageTransfer(target.transfer))
+			}
+
+			require.Nil(t, c.WritePPM(&buf, options...))
+			require.Equal(t, "P3\n3 1\n255\n"+target.ans+"\n", buf.String())
+		})
+	}
+}
+
+func Test_Read_PPM_With_Transfer(t *testing.T) {
+	c, err := ReadPPM(strings.NewReader("P3\n1 1\n255\n188 0 255\n"), ImageTransfer(SRGBTransfer{}))
+	require.Nil(t, err)
+	//188はsRGBで0.5に一番近い値
+	require.True(t, math.Abs(0.5-c.Pixels[0][0].Red) < 0.005, c.Pixels[0][0])
+	require.Equal(t, 0.0, c.Pixels[0][0].Green)
+	require.Equal(t, 1.0, c.Pixels[0][0].Blue)
+
+	//sRGBで書き出して読み直すとほぼ元に戻る
+	src := NewCanvas(1, 1)
+	src.WritePixel(0, 0, NewColor(0.3, 0.6, 0.05))
+
+	var buf bytes.Buffer
+	require.Nil(t, src.WriteBinaryPPM(&buf, ImageTransfer(SRGBTransfer{}), ImageMaxValue(65535)))
+
+	read, err := ReadPPM(&buf, ImageTransfer(SRGBTransfer{}))
+	require.Nil(t, err)
+	require.True(t, colorCompare(src.Pixels[0][0], read.Pixels[0][0]), read.Pixels[0][0])
+}
+
+func Test_Encode_NaN_As_Zero(t *testing.T) {
+	c := NewCanvas(1, 1)
+	c.WritePixel(0, 0, NewColor(math.NaN(), 0, 0))
+
+	for _, transfer := range []Transfer{nil, LinearTransfer{}, NewGammaTransfer(2.2), SRGBTransfer{}} {
+		var options []ImageOption
+		if transfer != nil {
+			options = append(options, ImageTransfer(transfer))
+		}
+
+		var buf bytes.Buffer
+		require.Nil(t, c.WritePPM(&buf, options...))
+		require.Equal(t, "P3\n1 1\n255\n0 0 0\n", buf.String(), transfer)
+
+		require.Equal(t, [3]int{0, 0, 0}, newImageOptions(options...).quantize(c.Pixels[0][0]), transfer)
+	}
+}
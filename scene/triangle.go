@@ -1,9 +1,6 @@
 package scene
 
-import (
-	"rayGo/calc"
-	"rayGo/util"
-)
+import "rayGo/calc"
 
 type Triangle struct {
 	*BaseShape
@@ -13,6 +10,11 @@ type Triangle struct {
 	E1        calc.Tuple4
 	E2        calc.Tuple4
 	NormalVec calc.Tuple4
+	T1        TexCoord
+	T2        TexCoord
+	T3        TexCoord
+	//T1~T3が設定されているか
+	HasTexCoord bool
 }
 
 func NewTriangle(p1, p2, p3 calc.Tuple4) Triangle {
@@ -29,10 +31,24 @@ func NewTriangle(p1, p2, p3 calc.Tuple4) Triangle {
 		e1,
 		e2,
 		normal_vec,
+		TexCoord{},
+		TexCoord{},
+		TexCoord{},
+		false,
 	}
 }
 
+//頂点ごとにtexture座標を持つTriangle
+func NewTexturedTriangle(p1, p2, p3 calc.Tuple4, t1, t2, t3 TexCoord) Triangle {
+	tri := NewTriangle(p1, p2, p3)
+	tri.T1, tri.T2, tri.T3 = t1, t2, t3
+	tri.HasTexCoord = true
+
+	return tri
+}
+
 var _ Shape = Triangle{}
+var _ TexturedShape = Triangle{}
 
 func (tri Triangle) calcLocalNormal(localPoint calc.Tuple4, hit Intersection) calc.Tuple4 {
 	return tri.NormalVec
@@ -48,7 +64,7 @@ func (tri Triangle) calcLocalIntersect(r Ray) (Intersections, error) {
 
 	det := calc.DotTuple(tri.E1, dir_cross_e2)
 
-	if util.IsNearlyEqualZero(det) {
+	if r.isNearlyZero(det) {
 		return Intersections{}, nil
 	}
 
@@ -71,8 +87,9 @@ func (tri Triangle) calcLocalIntersect(r Ray) (Intersections, error) {
 
 	t := f * calc.DotTuple(tri.E2, origin_cross_e1)
 
+	//texture座標の補間に使うのでu,vの値をセット
 	return AggregateIntersection(&Intersection{
-		t, tri, 0, 0,
+		t, tri, u, v,
 	}), nil
 }
 
@@ -80,6 +97,14 @@ func (tri Triangle) Intersect(r Ray) (Intersections, error) {
 	return tri.ShapeIntersect(r, tri.calcLocalIntersect)
 }
 
+func (tri Triangle) TexCoordAt(hit Intersection) (TexCoord, bool) {
+	if !tri.HasTexCoord {
+		return TexCoord{}, false
+	}
+
+	return interpolateTexCoord(hit, tri.T1, tri.T2, tri.T3), true
+}
+
 func (tri Triangle) GetMaterial() *Material {
 	return tri.Material
 }
@@ -91,3 +116,7 @@ func (tri Triangle) SetMaterial(m *Material) {
 func (tri Triangle) IsInclude(s Shape) bool {
 	return tri == s
 }
+
+func (tri Triangle) Bounds() BoundingBox {
+	return EmptyBoundingBox().AddPoint(tri.P1).AddPoint(tri.P2).AddPoint(tri.P3)
+}
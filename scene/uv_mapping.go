@@ -0,0 +1,142 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//object空間の点を2次元の(u, v)に移す、u,vはどちらも0から1
+type UVMapping func(point calc.Tuple4) (u, v float64)
+
+var (
+	_ UVMapping = SphericalMap
+	_ UVMapping = PlanarMap
+	_ UVMapping = CubicMap
+	_ UVMapping = CylindricalMap
+	_ UVMapping = ConicalMap
+)
+
+//球の中心からの向きで決める、uは経度でvは緯度
+//uは-zの向きから見て右回り、vは真下が0で真上が1
+func SphericalMap(point calc.Tuple4) (u, v float64) {
+	radius := math.Sqrt(point[0]*point[0] + point[1]*point[1] + point[2]*point[2])
+	if radius == 0 {
+		return 0.5, 0.5
+	}
+
+	u = azimuthU(point)
+	phi := math.Acos(math.Max(-1, math.Min(1, point[1]/radius)))
+	v = 1 - phi/math.Pi
+
+	return u, v
+}
+
+//xz平面に1×1のtileを敷き詰める
+func PlanarMap(point calc.Tuple4) (u, v float64) {
+	return fract(point[0]), fract(point[2])
+}
+
+//uは軸の周りの角度、vはyの小数部分なので高さ1ごとに繰り返す
+func CylindricalMap(point calc.Tuple4) (u, v float64) {
+	return azimuthU(point), fract(point[1])
+}
+
+//円錐も軸の周りの角度とyで決める、頂点(原点)ではuは0.5とする
+func ConicalMap(point calc.Tuple4) (u, v float64) {
+	if point[0] == 0 && point[2] == 0 {
+		return 0.5, fract(point[1])
+	}
+
+	return azimuthU(point), fract(point[1])
+}
+
+//立方体は点がどの面にあるかを決めてから面ごとの(u, v)にする
+func CubicMap(point calc.Tuple4) (u, v float64) {
+	return CubeFaceUV(CubeFaceFromPoint(point), point)
+}
+
+//y軸の周りの角度を0から1にする
+func azimuthU(point calc.Tuple4) float64 {
+	theta := math.Atan2(point[0], point[2])
+	rawU := theta / (2 * math.Pi)
+
+	return 1 - (rawU + 0.5)
+}
+
+//負の数でも0から1の間になるようにする
+func fract(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+type CubeFace int
+
+const (
+	CubeFaceLeft CubeFace = iota
+	CubeFaceRight
+	CubeFaceFront
+	CubeFaceBack
+	CubeFaceUp
+	CubeFaceDown
+)
+
+//絶対値が一番大きい軸の面、frontは-zではなく+zの面
+func CubeFaceFromPoint(point calc.Tuple4) CubeFace {
+	x, y, z := point[0], point[1], point[2]
+	coord := math.Max(math.Abs(x), math.Max(math.Abs(y), math.Abs(z)))
+
+	switch coord {
+	case x:
+		return CubeFaceRight
+	case -x:
+		return CubeFaceLeft
+	case y:
+		return CubeFaceUp
+	case -y:
+		return CubeFaceDown
+	case z:
+		return CubeFaceFront
+	default:
+		return CubeFaceBack
+	}
+}
+
+//各面を外から見たときに左下が(0, 0)、右上が(1, 1)になる
+func CubeFaceUV(face CubeFace, point calc.Tuple4) (u, v float64) {
+	x, y, z := point[0], point[1], point[2]
+
+	switch face {
+	case CubeFaceFront:
+		return cubeUV(x + 1), cubeUV(y + 1)
+	case CubeFaceBack:
+		return cubeUV(1 - x), cubeUV(y + 1)
+	case CubeFaceLeft:
+		return cubeUV(z + 1), cubeUV(y + 1)
+	case CubeFaceRight:
+		return cubeUV(1 - z), cubeUV(y + 1)
+	case CubeFaceUp:
+		return cubeUV(x + 1), cubeUV(1 - z)
+	default:
+		return cubeUV(x + 1), cubeUV(z + 1)
+	}
+}
+
+//-1から1の座標を0から1にする
+func cubeUV(x float64) float64 {
+	return math.Mod(math.Mod(x, 2)+2, 2) / 2
+}
+
+//shapeの形に合ったmapping、それ以外のshapeには球面を使う
+func UVMappingFor(shape Shape) UVMapping {
+	switch shape.(type) {
+	case Plane:
+		return PlanarMap
+	case Cube:
+		return CubicMap
+	case Cyliner:
+		return CylindricalMap
+	case Cone:
+		return ConicalMap
+	default:
+		return SphericalMap
+	}
+}
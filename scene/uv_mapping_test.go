@@ -0,0 +1,130 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type uvTarget struct {
+	point calc.Tuple4
+	u     float64
+	v     float64
+}
+
+func requireUV(t *testing.T, mapping UVMapping, targets []uvTarget) {
+	for _, target := range targets {
+		u, v := mapping(target.point)
+		require.True(t, math.Abs(target.u-u) < 0.0001, target)
+		require.True(t, math.Abs(target.v-v) < 0.0001, target)
+	}
+}
+
+func Test_Spherical_Map(t *testing.T) {
+	requireUV(t, SphericalMap, []uvTarget{
+		{calc.NewPoint(0, 0, -1), 0, 0.5},
+		{calc.NewPoint(1, 0, 0), 0.25, 0.5},
+		{calc.NewPoint(0, 0, 1), 0.5, 0.5},
+		{calc.NewPoint(-1, 0, 0), 0.75, 0.5},
+		{calc.NewPoint(0, 1, 0), 0.5, 1},
+		{calc.NewPoint(0, -1, 0), 0.5, 0},
+		{calc.NewPoint(math.Sqrt(2)/2, math.Sqrt(2)/2, 0), 0.25, 0.75},
+		//半径は関係ない
+		{calc.NewPoint(3, 0, 0), 0.25, 0.5},
+	})
+}
+
+func Test_Planar_Map(t *testing.T) {
+	requireUV(t, PlanarMap, []uvTarget{
+		{calc.NewPoint(0.25, 0, 0.5), 0.25, 0.5},
+		{calc.NewPoint(0.25, 0, -0.25), 0.25, 0.75},
+		{calc.NewPoint(0.25, 0.5, -0.25), 0.25, 0.75},
+		{calc.NewPoint(1.25, 0, 0.5), 0.25, 0.5},
+		{calc.NewPoint(0.25, 0, -1.75), 0.25, 0.25},
+		{calc.NewPoint(1, 0, -1), 0, 0},
+		{calc.NewPoint(0, 0, 0), 0, 0},
+	})
+}
+
+func Test_Cylindrical_Map(t *testing.T) {
+	requireUV(t, CylindricalMap, []uvTarget{
+		{calc.NewPoint(0, 0, -1), 0, 0},
+		{calc.NewPoint(0, 0.5, -1), 0, 0.5},
+		{calc.NewPoint(0, 1, -1), 0, 0},
+		{calc.NewPoint(0.70711, 0.5, -0.70711), 0.125, 0.5},
+		{calc.NewPoint(1, 0.5, 0), 0.25, 0.5},
+		{calc.NewPoint(0.70711, 0.5, 0.70711), 0.375, 0.5},
+		{calc.NewPoint(0, -0.25, 1), 0.5, 0.75},
+		{calc.NewPoint(-0.70711, 0.5, 0.70711), 0.625, 0.5},
+		{calc.NewPoint(-1, 1.25, 0), 0.75, 0.25},
+		{calc.NewPoint(-0.70711, 0.5, -0.70711), 0.875, 0.5},
+	})
+}
+
+func Test_Conical_Map(t *testing.T) {
+	requireUV(t, ConicalMap, []uvTarget{
+		{calc.NewPoint(0, 0, 0), 0.5, 0},
+		{calc.NewPoint(0, -0.5, -0.5), 0, 0.5},
+		{calc.NewPoint(0.5, 0.5, 0), 0.25, 0.5},
+		{calc.NewPoint(0, -0.25, 0.25), 0.5, 0.75},
+		{calc.NewPoint(-1, -1, 0), 0.75, 0},
+	})
+}
+
+func Test_Cube_Face_From_Point(t *testing.T) {
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   CubeFace
+	}{
+		{calc.NewPoint(-1, 0.5, -0.25), CubeFaceLeft},
+		{calc.NewPoint(1.1, -0.75, 0.8), CubeFaceRight},
+		{calc.NewPoint(0.1, 0.6, 0.9), CubeFaceFront},
+		{calc.NewPoint(-0.7, 0, -2), CubeFaceBack},
+		{calc.NewPoint(0.5, 1, 0.9), CubeFaceUp},
+		{calc.NewPoint(-0.2, -1.3, 1.1), CubeFaceDown},
+	} {
+		require.Equal(t, target.ans, CubeFaceFromPoint(target.point), target)
+	}
+}
+
+func Test_Cubic_Map(t *testing.T) {
+	requireUV(t, CubicMap, []uvTarget{
+		//front
+		{calc.NewPoint(-0.5, 0.5, 1), 0.25, 0.75},
+		{calc.NewPoint(0.5, -0.5, 1), 0.75, 0.25},
+		//back
+		{calc.NewPoint(0.5, 0.5, -1), 0.25, 0.75},
+		{calc.NewPoint(-0.5, -0.5, -1), 0.75, 0.25},
+		//left
+		{calc.NewPoint(-1, 0.5, -0.5), 0.25, 0.75},
+		{calc.NewPoint(-1, -0.5, 0.5), 0.75, 0.25},
+		//right
+		{calc.NewPoint(1, 0.5, 0.5), 0.25, 0.75},
+		{calc.NewPoint(1, -0.5, -0.5), 0.75, 0.25},
+		//up
+		{calc.NewPoint(-0.5, 1, -0.5), 0.25, 0.75},
+		{calc.NewPoint(0.5, 1, 0.5), 0.75, 0.25},
+		//down
+		{calc.NewPoint(-0.5, -1, 0.5), 0.25, 0.75},
+		{calc.NewPoint(0.5, -1, -0.5), 0.75, 0.25},
+	})
+}
+
+func Test_UV_Mapping_For_Shape(t *testing.T) {
+	for _, target := range []struct {
+		shape Shape
+		ans   UVMapping
+	}{
+		{NewSphere(1), SphericalMap},
+		{NewPlane(), PlanarMap},
+		{NewCube(), CubicMap},
+		{NewCyliner(), CylindricalMap},
+		{NewCone(), ConicalMap},
+		{NewGroup(), SphericalMap},
+	} {
+		require.Equal(t, reflect.ValueOf(target.ans).Pointer(), reflect.ValueOf(UVMappingFor(target.shape)).Pointer(), target.shape)
+	}
+}
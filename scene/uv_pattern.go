@@ -0,0 +1,110 @@
+package scene
+
+import (
+	"math"
+	"rayGo/calc"
+)
+
+//(u, v)から色を決めるpattern、TextureMapPatternなどでshapeに貼る
+type UVPattern interface {
+	UVPatternAt(u, v float64) Color
+}
+
+//uをWidth個、vをHeight個に分けた市松模様
+type UVCheckers struct {
+	Width  float64
+	Height float64
+	Color1 Color
+	Color2 Color
+}
+
+var _ UVPattern = UVCheckers{}
+
+func NewUVCheckers(width, height float64, c1, c2 Color) UVCheckers {
+	return UVCheckers{
+		Width:  width,
+		Height: height,
+		Color1: c1,
+		Color2: c2,
+	}
+}
+
+func (c UVCheckers) UVPatternAt(u, v float64) Color {
+	u2 := int(math.Floor(u * c.Width))
+	v2 := int(math.Floor(v * c.Height))
+
+	if (u2+v2)%2 == 0 {
+		return c.Color1
+	}
+
+	return c.Color2
+}
+
+//UVPatternをUVMappingでshapeに貼るpattern
+//Mappingがnilのときは当たったshapeに合わせてUVMappingForで選ぶ
+type TextureMapPattern struct {
+	*BasePattern
+	UVPattern UVPattern
+	Mapping   UVMapping
+}
+
+var _ Pattern = TextureMapPattern{}
+
+func NewTextureMapPattern(uvPattern UVPattern, mapping UVMapping) TextureMapPattern {
+	return TextureMapPattern{
+		NewBasePattern(),
+		uvPattern,
+		mapping,
+	}
+}
+
+//shapeが分からないのでMappingがnilなら球面に貼る
+func (tp TextureMapPattern) PatternAt(point calc.Tuple4) Color {
+	mapping := tp.Mapping
+	if mapping == nil {
+		mapping = SphericalMap
+	}
+
+	return tp.UVPattern.UVPatternAt(mapping(point))
+}
+
+//meshの頂点にtexture座標があればUVMappingより優先する
+func (tp TextureMapPattern) PatternAtShape(world_point calc.Tuple4, shape Shape) (Color, error) {
+	if texCoord, ok := hitTexCoord(shape); ok {
+		return tp.UVPattern.UVPatternAt(texCoord.U, texCoord.V), nil
+	}
+
+	mapping := tp.Mapping
+	if mapping == nil {
+		mapping = UVMappingFor(shape)
+	}
+
+	return tp.PatternAtShapeOnBase(world_point, shape, func(point calc.Tuple4) Color {
+		return tp.UVPattern.UVPatternAt(mapping(point))
+	})
+}
+
+//立方体の面ごとに別のUVPatternを貼る、FacesはCubeFaceの順
+type CubeMapPattern struct {
+	*BasePattern
+	Faces [6]UVPattern
+}
+
+var _ Pattern = CubeMapPattern{}
+
+func NewCubeMapPattern(left, right, front, back, up, down UVPattern) CubeMapPattern {
+	return CubeMapPattern{
+		NewBasePattern(),
+		[6]UVPattern{left, right, front, back, up, down},
+	}
+}
+
+func (cp CubeMapPattern) PatternAt(point calc.Tuple4) Color {
+	face := CubeFaceFromPoint(point)
+
+	return cp.Faces[face].UVPatternAt(CubeFaceUV(face, point))
+}
+
+func (cp CubeMapPattern) PatternAtShape(world_point calc.Tuple4, shape Shape) (Color, error) {
+	return cp.PatternAtShapeOnBase(world_point, shape, cp.PatternAt)
+}
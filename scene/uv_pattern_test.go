@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"rayGo/calc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UV_Checkers(t *testing.T) {
+	checkers := NewUVCheckers(2, 2, Black, White)
+
+	for _, target := range []struct {
+		u   float64
+		v   float64
+		ans Color
+	}{
+		{0, 0, Black},
+		{0.5, 0, White},
+		{0, 0.5, White},
+		{0.5, 0.5, Black},
+		{1, 1, Black},
+	} {
+		require.Equal(t, target.ans, checkers.UVPatternAt(target.u, target.v), target)
+	}
+}
+
+//極の近くでも升目が歪まずに球に貼れる
+func Test_Texture_Map_Pattern_On_Sphere(t *testing.T) {
+	pattern := NewTextureMapPattern(NewUVCheckers(16, 8, Black, White), SphericalMap)
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   Color
+	}{
+		{calc.NewPoint(0.4315, 0.4670, 0.7719), White},
+		{calc.NewPoint(-0.9654, 0.2552, -0.0534), Black},
+		{calc.NewPoint(0.1039, 0.7090, 0.6975), White},
+		{calc.NewPoint(-0.4986, -0.7856, -0.3663), Black},
+		{calc.NewPoint(-0.0317, -0.9395, 0.3411), Black},
+		{calc.NewPoint(0.4809, -0.7721, 0.4154), Black},
+		{calc.NewPoint(0.0285, -0.9612, -0.2745), Black},
+		{calc.NewPoint(-0.5734, -0.2162, -0.7903), White},
+		{calc.NewPoint(0.7688, -0.1470, 0.6223), Black},
+		{calc.NewPoint(-0.7652, 0.2175, 0.6060), Black},
+	} {
+		require.Equal(t, target.ans, pattern.PatternAt(target.point), target)
+	}
+}
+
+//Mappingを指定しなければ当たったshapeに合ったものを使う
+func Test_Texture_Map_Pattern_Picks_Mapping_From_Shape(t *testing.T) {
+	pattern := NewTextureMapPattern(NewUVCheckers(2, 2, Black, White), nil)
+
+	//planeではxz平面のtile
+	c, err := pattern.PatternAtShape(calc.NewPoint(0.75, 0, 0.25), NewPlane())
+	require.Nil(t, err)
+	require.Equal(t, White, c)
+
+	//球では経度と緯度
+	c, err = pattern.PatternAtShape(calc.NewPoint(1, 0, 0), NewSphere(1))
+	require.Nil(t, err)
+	require.Equal(t, White, c)
+
+	c, err = pattern.PatternAtShape(calc.NewPoint(0, 0, -1), NewSphere(1))
+	require.Nil(t, err)
+	require.Equal(t, White, c)
+
+	c, err = pattern.PatternAtShape(calc.NewPoint(-1, -0.1, 0), NewSphere(1))
+	require.Nil(t, err)
+	require.Equal(t, White, c)
+}
+
+func Test_Texture_Map_Pattern_Uses_Shape_And_Pattern_Transform(t *testing.T) {
+	pattern := NewTextureMapPattern(NewUVCheckers(2, 2, Black, White), PlanarMap)
+	require.Nil(t, pattern.SetTransform(calc.NewScale(2, 2, 2)))
+
+	plane := NewPlane()
+	require.Nil(t, plane.SetTransform(calc.NewTranslation(1, 0, 0)))
+
+	//object空間では(0.5, 0, 0.5)、pattern空間では(0.25, 0, 0.25)
+	c, err := pattern.PatternAtShape(calc.NewPoint(1.5, 0, 0.5), plane)
+	require.Nil(t, err)
+	require.Equal(t, Black, c)
+}
+
+type solidUV struct {
+	color Color
+}
+
+func (s solidUV) UVPatternAt(u, v float64) Color {
+	return s.color
+}
+
+func Test_Cube_Map_Pattern(t *testing.T) {
+	red := NewColor(1, 0, 0)
+	yellow := NewColor(1, 1, 0)
+	brown := NewColor(1, 0.5, 0)
+	green := NewColor(0, 1, 0)
+	cyan := NewColor(0, 1, 1)
+	blue := NewColor(0, 0, 1)
+
+	pattern := NewCubeMapPattern(solidUV{yellow}, solidUV{red}, solidUV{cyan}, solidUV{green}, solidUV{brown}, solidUV{blue})
+
+	for _, target := range []struct {
+		point calc.Tuple4
+		ans   Color
+	}{
+		{calc.NewPoint(-1, 0, 0), yellow},
+		{calc.NewPoint(1, 0, 0), red},
+		{calc.NewPoint(0, 0, 1), cyan},
+		{calc.NewPoint(0, 0, -1), green},
+		{calc.NewPoint(0, 1, 0), brown},
+		{calc.NewPoint(0, -1, 0), blue},
+	} {
+		c, err := pattern.PatternAtShape(target.point, NewCube())
+		require.Nil(t, err)
+		require.Equal(t, target.ans, c, target)
+	}
+
+	//面の中では(u, v)がそのまま渡る
+	face := NewCubeMapPattern(nil, nil, NewUVCheckers(2, 2, Black, White), nil, nil, nil)
+	require.Equal(t, Black, face.PatternAt(calc.NewPoint(-0.5, -0.5, 1)))
+	require.Equal(t, White, face.PatternAt(calc.NewPoint(0.5, -0.5, 1)))
+}
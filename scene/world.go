@@ -6,16 +6,14 @@ import (
 	"sort"
 )
 
-var DefaultRemaing = 5
-
 type World struct {
-	Light   Light
+	Lights  []LightSource
 	Objects []Shape
 }
 
-func NewWorld(light Light, objects ...Shape) *World {
+func NewWorld(light LightSource, objects ...Shape) *World {
 	return &World{
-		Light:   light,
+		Lights:  []LightSource{light},
 		Objects: objects,
 	}
 }
@@ -24,6 +22,10 @@ func (w *World) AddObjects(objects ...Shape) {
 	w.Objects = append(w.Objects, objects...)
 }
 
+func (w *World) AddLights(lights ...LightSource) {
+	w.Lights = append(w.Lights, lights...)
+}
+
 func (w *World) Intersect(r Ray) (Intersections, error) {
 
 	count := 0
@@ -49,7 +51,7 @@ func (w *World) Intersect(r Ray) (Intersections, error) {
 	}, nil
 }
 
-func (w *World) RefractedColor(comps PreComps, remainingReflection, remainingRefraction int) (Color, error) {
+func (w *World) RefractedColor(comps PreComps, settings *RenderSettings, remainingReflection, remainingRefraction int) (Color, error) {
 	if remainingRefraction <= 0 {
 		return Black, nil
 	}
@@ -74,7 +76,7 @@ func (w *World) RefractedColor(comps PreComps, remainingReflection, remainingRef
 	)
 
 	refract_ray := NewRay(comps.UnderPoint, direction)
-	refract_color, err := w.ColorAt(refract_ray, remainingReflection, remainingRefraction-1)
+	refract_color, err := w.ColorAt(refract_ray, settings, remainingReflection, remainingRefraction-1)
 
 	if err != nil {
 		return Black, err
@@ -88,7 +90,7 @@ func (w *World) RefractedColor(comps PreComps, remainingReflection, remainingRef
 	return TupletoColor(colorTuple), nil
 }
 
-func (w *World) ReflectedColor(comps PreComps, remainingReflection, remainingRefraction int) (Color, error) {
+func (w *World) ReflectedColor(comps PreComps, settings *RenderSettings, remainingReflection, remainingRefraction int) (Color, error) {
 
 	if remainingReflection <= 0 {
 		return Black, nil
@@ -100,7 +102,7 @@ func (w *World) ReflectedColor(comps PreComps, remainingReflection, remainingRef
 	}
 
 	reflect_ray := NewRay(comps.OverPoint, comps.ReflectVec)
-	color, err := w.ColorAt(reflect_ray, remainingReflection-1, remainingRefraction)
+	color, err := w.ColorAt(reflect_ray, settings, remainingReflection-1, remainingRefraction)
 	if err != nil {
 		return Color{}, err
 	}
@@ -132,32 +134,81 @@ func (w *World) ApplyFresnel(comps PreComps, surface, reflected, refracted Color
 
 }
 
-//rayとobjectの交点とずらしたOverPointを使わないと自分自身が自分と重なっている点として判定されてしまう
-func (w *World) ShadeHit(comps PreComps, remainingReflection, remainingRefraction int) (Color, error) {
+//ambientは光源の数によらず一度だけ、光源の色の平均を環境光とみなす
+//光源ごとに見えている割合を求めてdiffuseとspecularを足し合わせる
+func (w *World) surfaceColor(comps PreComps, settings *RenderSettings) (Color, error) {
+	if len(w.Lights) == 0 {
+		return Black, nil
+	}
 
-	in_shadow, err := w.IsShadowed(comps.OverPoint)
+	m := comps.Object.GetMaterial()
+	materialColor, err := m.GetMaterialColor(comps.RayPoint, comps.shadingObject())
 	if err != nil {
 		return Color{}, err
 	}
 
-	sufaceColor, err := w.Light.Lighting(
-		comps.Object.GetMaterial(),
+	ambientLight := Black
+	for _, light := range w.Lights {
+		ambientLight = ambientLight.Add(light.GetIntensity())
+	}
+
+	color := ambientColor(m, materialColor, ambientLight.MulByScalar(1/float64(len(w.Lights))))
+
+	for _, light := range w.Lights {
+		direct, err := w.directLighting(light, m, materialColor, comps, settings)
+		if err != nil {
+			return Color{}, err
+		}
+
+		color = color.Add(direct)
+	}
+
+	return color, nil
+}
+
+//影はOverPointから調べて、diffuseとspecularはRayPointで計算する
+//sampleを取る光源ではRayPointからsampleを取り直すと影を調べた点と別の点になるので、同じsampleを渡す
+func (w *World) directLighting(light LightSource, m *Material, materialColor Color, comps PreComps, settings *RenderSettings) (Color, error) {
+	if sampled, ok := light.(sampledLight); ok {
+		samples := sampled.samplePoints(comps.OverPoint)
+
+		intensity, err := sampledIntensityAt(samples, comps.OverPoint, w, settings)
+		if err != nil {
+			return Color{}, err
+		}
+
+		return sampledDirectLighting(samples, sampled.GetIntensity(), m, materialColor, comps.RayPoint, comps.EyeVec, comps.NormalVec, intensity), nil
+	}
+
+	intensity, err := light.IntensityAt(comps.OverPoint, w, settings)
+	if err != nil {
+		return Color{}, err
+	}
+
+	return light.DirectLighting(
+		m,
+		materialColor,
 		comps.RayPoint,
 		comps.EyeVec,
 		comps.NormalVec,
-		in_shadow,
-		comps.Object,
-	)
+		intensity,
+	), nil
+}
+
+//rayとobjectの交点とずらしたOverPointを使わないと自分自身が自分と重なっている点として判定されてしまう
+func (w *World) ShadeHit(comps PreComps, settings *RenderSettings, remainingReflection, remainingRefraction int) (Color, error) {
+
+	sufaceColor, err := w.surfaceColor(comps, settings)
 	if err != nil {
 		return Color{}, err
 	}
 
-	reflected, err := w.ReflectedColor(comps, remainingReflection, remainingRefraction)
+	reflected, err := w.ReflectedColor(comps, settings, remainingReflection, remainingRefraction)
 	if err != nil {
 		return Color{}, err
 	}
 
-	refracted, err := w.RefractedColor(comps, remainingReflection, remainingRefraction)
+	refracted, err := w.RefractedColor(comps, settings, remainingReflection, remainingRefraction)
 	if err != nil {
 		return Color{}, err
 	}
@@ -169,19 +220,27 @@ func (w *World) ShadeHit(comps PreComps, remainingReflection, remainingRefractio
 //光源とpointを結んでRayをつくってRayとWorldのIntersectionを求める
 //hitがあり、tがdistanceより小さければpointはShadow
 //それ以外はShadowでない
-func (w *World) IsShadowed(point calc.Tuple4) (bool, error) {
+func (w *World) IsShadowed(lightPosition, point calc.Tuple4, settings *RenderSettings) (bool, error) {
 
-	v := calc.SubTuple(w.Light.Position, point)
+	v := calc.SubTuple(lightPosition, point)
 	distance := v.Magnitude()
 	direction := v.Normalize()
 
+	return w.IsShadowedAlong(direction, distance, point, settings)
+}
+
+//pointからdirectionの向きにdistanceまでの間に何かあればShadow
+//DirectionalLightのように無限に遠い光源はdistanceにutil.Infを渡す
+func (w *World) IsShadowedAlong(direction calc.Tuple4, distance float64, point calc.Tuple4, settings *RenderSettings) (bool, error) {
+
 	ray := NewRay(point, direction)
+	ray.Tolerance = settings.Epsilon
 	xs, err := w.Intersect(ray)
 	if err != nil {
 		return false, err
 	}
 
-	hit := GenerateHit(xs)
+	hit := generateHitAfter(xs, settings.MinHitDistance)
 
 	if hit != nil && hit.Time < distance {
 		return true, nil
@@ -190,48 +249,29 @@ func (w *World) IsShadowed(point calc.Tuple4) (bool, error) {
 	return false, nil
 }
 
-func (w *World) ColorAt(ray Ray, remainingReflection, remainingRefraction int) (Color, error) {
+//remainingはあと何回反射、屈折を追えるか、最初はsettingsのMax...Depthを渡す
+//交差判定はsettingsのEpsilonで行うので、反射や屈折で作ったrayにも同じ値が付く
+func (w *World) ColorAt(ray Ray, settings *RenderSettings, remainingReflection, remainingRefraction int) (Color, error) {
+	ray.Tolerance = settings.Epsilon
 	xs, err := w.Intersect(ray)
 	if err != nil {
 		return Color{}, err
 	}
 
-	hit := GenerateHit(xs)
+	hit := generateHitAfter(xs, settings.MinHitDistance)
 
 	if hit == nil {
-		return Black, nil
+		return settings.Background.ColorFor(ray.Direction), nil
 	}
 
-	comps, err := PrepareComputations(*hit, ray, xs)
+	comps, err := prepareComputations(*hit, ray, xs, settings.ShadowBias)
 
 	if err != nil {
 		return Color{}, err
 	}
 
-	return w.ShadeHit(comps, remainingReflection, remainingRefraction)
-
-}
-
-func (w *World) Render(camera Camera) (*Canvas, error) {
-	canvas := NewCanvas(int(camera.VSize), int(camera.HSize))
-
-	for y := 0; y < canvas.Height; y++ {
-		for x := 0; x < canvas.Width; x++ {
-			ray, err := camera.RayForPixel(float64(x), float64(y))
-			if err != nil {
-				return nil, err
-			}
-
-			color, err := w.ColorAt(ray, DefaultRemaing, DefaultRemaing)
-			if err != nil {
-				return nil, err
-			}
-
-			canvas.WritePixel(x, y, color)
-		}
-	}
+	return w.ShadeHit(comps, settings, remainingReflection, remainingRefraction)
 
-	return canvas, nil
 }
 
 func DefaultWorld() *World {
@@ -71,16 +71,86 @@ func Test_Shading_Intersection(t *testing.T) {
 	comps, err := PrepareComputations(i, r, Intersections{})
 	require.Nil(t, err)
 
-	c, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	c, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), c))
 
 }
 
+func Test_Shading_Intersection_With_Multiple_Lights(t *testing.T) {
+	w := DefaultWorld()
+	require.Equal(t, 1, len(w.Lights))
+
+	//同じ光源を二つ置くとdiffuseとspecularは二倍になるが、ambient(0.1*色)は一度だけ
+	w.AddLights(NewLight(calc.NewPoint(-10, 10, -10), NewColor(1, 1, 1)))
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+
+	comps, err := PrepareComputations(CreateIntersection(4, w.Objects[0]), r, Intersections{})
+	require.Nil(t, err)
+
+	c, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(NewColor(0.68132, 0.85166, 0.511), c))
+}
+
+func Test_Shading_Intersection_With_One_Light_Shadowed(t *testing.T) {
+	s1 := NewSphere(1)
+	s2 := NewSphere(1)
+	s2.SetTransform(calc.NewTranslation(0, 0, 10))
+
+	//一つ目の光源はs1に遮られて何も足さない、二つ目の光源はs2を正面から照らす
+	//ambientは光源が二つでも一度だけなので0.1+0.9+0.9
+	w := NewWorld(NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1)), s1, s2)
+	w.AddLights(NewLight(calc.NewPoint(0, 0, 5), NewColor(1, 1, 1)))
+
+	ray := NewRay(calc.NewPoint(0, 0, 5), calc.NewVector(0, 0, 1))
+	comps, err := PrepareComputations(CreateIntersection(4, s2), ray, Intersections{})
+	require.Nil(t, err)
+
+	c, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(NewColor(1.9, 1.9, 1.9), c))
+}
+
+func Test_Adding_A_Light_Does_Not_Brighten_Shadowed_Surface(t *testing.T) {
+	s1 := NewSphere(1)
+	s2 := NewSphere(1)
+	s2.SetTransform(calc.NewTranslation(0, 0, 10))
+
+	//s2の裏側はどちらの光源からも照らされないのでambientだけ
+	ray := NewRay(calc.NewPoint(0, 0, 15), calc.NewVector(0, 0, -1))
+	comps, err := PrepareComputations(CreateIntersection(4, s2), ray, Intersections{})
+	require.Nil(t, err)
+
+	w := NewWorld(NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1)), s1, s2)
+	one, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	w.AddLights(NewLight(calc.NewPoint(0, 0, -20), NewColor(1, 1, 1)))
+	two, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(NewColor(0.1, 0.1, 0.1), one))
+	require.Equal(t, one, two)
+}
+
+func Test_Shading_Intersection_Without_Lights(t *testing.T) {
+	w := DefaultWorld()
+	w.Lights = nil
+	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
+
+	c, err := w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
+	require.Nil(t, err)
+
+	require.True(t, colorCompare(Black, c))
+}
+
 func Test_Shading_Intersection_When_Ray_Is_Inside(t *testing.T) {
 	w := DefaultWorld()
-	w.Light = NewLight(calc.NewPoint(0, 0.25, 0), NewColor(1, 1, 1))
+	w.Lights = []LightSource{NewLight(calc.NewPoint(0, 0.25, 0), NewColor(1, 1, 1))}
 	r := NewRay(calc.NewPoint(0, 0, 0), calc.NewVector(0, 0, 1))
 
 	shape := w.Objects[1]
@@ -89,7 +159,7 @@ func Test_Shading_Intersection_When_Ray_Is_Inside(t *testing.T) {
 	comps, err := PrepareComputations(i, r, Intersections{})
 	require.Nil(t, err)
 
-	c, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	c, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0.90498, 0.90498, 0.90498), c))
@@ -100,7 +170,7 @@ func Test_Color_At_When_Ray_Misses(t *testing.T) {
 	w := DefaultWorld()
 	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 1, 0))
 
-	c, err := w.ColorAt(r, DefaultRemaing, DefaultRemaing)
+	c, err := w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 
 	require.Nil(t, err)
 	require.True(t, colorCompare(NewColor(0, 0, 0), c))
@@ -111,7 +181,7 @@ func Test_Color_At_When_Ray_Hits(t *testing.T) {
 	w := DefaultWorld()
 	r := NewRay(calc.NewPoint(0, 0, -5), calc.NewVector(0, 0, 1))
 
-	c, err := w.ColorAt(r, DefaultRemaing, DefaultRemaing)
+	c, err := w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 
 	require.Nil(t, err)
 	require.True(t, colorCompare(NewColor(0.38066, 0.47583, 0.2855), c))
@@ -133,7 +203,7 @@ func Test_Color_At_With_Intersection_Behind_Ray(t *testing.T) {
 
 	r := NewRay(calc.NewPoint(0, 0, 0.75), calc.NewVector(0, 0, -1))
 
-	c, err := w.ColorAt(r, DefaultRemaing, DefaultRemaing)
+	c, err := w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 
 	require.Nil(t, err)
 	require.True(t, colorCompare(inner.GetMaterial().Color, c))
@@ -172,7 +242,7 @@ func TestIsShadowed(t *testing.T) {
 	} {
 		t.Run(target.title, func(t *testing.T) {
 
-			isShadow, err := w.IsShadowed(target.p)
+			isShadow, err := w.IsShadowed(w.Lights[0].(Light).Position, target.p, DefaultRenderSettings())
 			require.Nil(t, err)
 			require.Equal(t, target.isShadow, isShadow)
 		})
@@ -195,7 +265,7 @@ func Test_Hit_OverPoint(t *testing.T) {
 func Test_ShadeHit_When_Given_IsShadow_is_True(t *testing.T) {
 	w := DefaultWorld()
 
-	w.Light = NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))
+	w.Lights = []LightSource{NewLight(calc.NewPoint(0, 0, -10), NewColor(1, 1, 1))}
 	s1 := NewSphere(1)
 	s2 := NewSphere(1)
 	s2.SetTransform(calc.NewTranslation(0, 0, 10))
@@ -208,7 +278,7 @@ func Test_ShadeHit_When_Given_IsShadow_is_True(t *testing.T) {
 	comps, err := PrepareComputations(i, ray, Intersections{})
 	require.Nil(t, err)
 
-	c, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	c, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 	require.True(t, colorCompare(NewColor(0.1, 0.1, 0.1), c))
 }
@@ -225,7 +295,7 @@ func Test_Reflected_Color_For_NonReflective_Material(t *testing.T) {
 	i := Intersection{1, shape, 0, 0}
 	comps, err := PrepareComputations(i, r, Intersections{})
 	require.Nil(t, err)
-	color, err := w.ReflectedColor(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.ReflectedColor(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0, 0, 0), color))
@@ -246,7 +316,7 @@ func Test_Reflected_Color_For_Reflective_Material(t *testing.T) {
 	i := Intersection{math.Sqrt(2), shape, 0, 0}
 	comps, err := PrepareComputations(i, r, Intersections{})
 	require.Nil(t, err)
-	color, err := w.ReflectedColor(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.ReflectedColor(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	util.SetEpsilon(0.0001)
@@ -268,7 +338,7 @@ func Test_Shade_Hit_For_Reflective_Material(t *testing.T) {
 	i := Intersection{math.Sqrt(2), shape, 0, 0}
 	comps, err := PrepareComputations(i, r, Intersections{})
 	require.Nil(t, err)
-	color, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	util.SetEpsilon(0.0001)
@@ -297,7 +367,7 @@ func Test_Avoid_Infinite_Recursion_For_Reflect(t *testing.T) {
 
 	//回数制限を設けて、
 	//ColorAt -> ShadeHit -> ReflectedColor -> ColorAtの無限ループを防ぐ
-	w.ColorAt(r, DefaultRemaing, DefaultRemaing)
+	w.ColorAt(r, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 
 	terminate := true
 	require.True(t, terminate)
@@ -429,7 +499,7 @@ func Test_Refracted_Color_On_Opaque_Surface(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[0], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.RefractedColor(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.RefractedColor(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0, 0, 0), color))
@@ -450,7 +520,7 @@ func Test_Avoid_Infinite_Recursion_on_RefractedColor(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[0], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.RefractedColor(comps, 0, 0)
+	color, err := w.RefractedColor(comps, DefaultRenderSettings(), 0, 0)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0, 0, 0), color))
@@ -473,7 +543,7 @@ func Test_Refracted_Color_Under_Total_Internal_Reflection(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[1], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.RefractedColor(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.RefractedColor(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0, 0, 0), color))
@@ -507,7 +577,7 @@ func Test_Refracted_Color_Under_Normal_Condition(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[2], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.RefractedColor(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.RefractedColor(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	util.SetEpsilon(0.0001)
@@ -541,7 +611,7 @@ func Test_ShadeHit_Transparent_Material(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[0], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0.93642, 0.68642, 0.68642), color))
@@ -575,7 +645,7 @@ func Test_ShadeHit_With_Reflective_Transparent_Marterial(t *testing.T) {
 	comps, err := PrepareComputations(*xs.Intersections[0], ray, xs)
 	require.Nil(t, err)
 
-	color, err := w.ShadeHit(comps, DefaultRemaing, DefaultRemaing)
+	color, err := w.ShadeHit(comps, DefaultRenderSettings(), DefaultMaxDepth, DefaultMaxDepth)
 	require.Nil(t, err)
 
 	require.True(t, colorCompare(NewColor(0.93391, 0.69643, 0.69243), color))
@@ -0,0 +1,73 @@
+package scenefile
+
+import (
+	"rayGo/scene"
+)
+
+//type: solidはcolor、gradientはbottomとtop、environmentはfileに画像を指定する
+//environmentの画像もtextureと同じくtransferを省くとsRGBとしてlinearに戻す
+func (l *loader) loadBackground(fields *mapping) error {
+	if l.hasBackground {
+		return l.errorf(fields.node, "scene has more than one background")
+	}
+
+	if err := fields.allow("add", "type", "color", "bottom", "top", "file", "transfer"); err != nil {
+		return err
+	}
+
+	typeNode, err := fields.require("type")
+	if err != nil {
+		return err
+	}
+
+	kind, err := l.string(typeNode)
+	if err != nil {
+		return err
+	}
+
+	var background scene.Background
+	switch kind {
+	case "solid":
+		c, err := fields.color("color", scene.Black)
+		if err != nil {
+			return err
+		}
+
+		background = scene.NewSolidBackground(c)
+	case "gradient":
+		bottom, err := fields.color("bottom", scene.White)
+		if err != nil {
+			return err
+		}
+
+		top, err := fields.color("top", scene.NewColor(0.5, 0.7, 1))
+		if err != nil {
+			return err
+		}
+
+		background = scene.NewGradientBackground(bottom, top)
+	case "environment":
+		transfer, transferName, err := l.imageTransfer(fields)
+		if err != nil {
+			return err
+		}
+
+		fileNode, err := fields.require("file")
+		if err != nil {
+			return err
+		}
+
+		image, err := l.loadImage(fileNode, transfer, transferName)
+		if err != nil {
+			return err
+		}
+
+		background = scene.NewEnvironmentMap(image)
+	default:
+		return l.errorf(typeNode, "unknown background type %q", kind)
+	}
+
+	l.settings.Background = background
+	l.hasBackground = true
+	return nil
+}
@@ -0,0 +1,133 @@
+package scenefile
+
+import (
+	"math"
+	"rayGo/calc"
+	"rayGo/scene"
+)
+
+func (l *loader) loadCamera(fields *mapping) error {
+	if l.camera != nil {
+		return l.errorf(fields.node, "scene has more than one camera")
+	}
+
+	if err := fields.allow("add", "type", "width", "height", "field-of-view",
+		"from", "to", "up", "aperture", "focal-distance", "view-width"); err != nil {
+		return err
+	}
+
+	kind := "perspective"
+	if typeNode, ok := fields.get("type"); ok {
+		var err error
+		if kind, err = l.string(typeNode); err != nil {
+			return err
+		}
+	}
+
+	width, err := fields.int("width", 100)
+	if err != nil {
+		return err
+	}
+
+	height, err := fields.int("height", 100)
+	if err != nil {
+		return err
+	}
+
+	if width < 1 || height < 1 {
+		return l.errorf(fields.node, "camera size must be positive, got %dx%d", width, height)
+	}
+
+	width, height = l.options.cameraSize(width, height)
+
+	fov, err := fields.float("field-of-view", math.Pi/3)
+	if err != nil {
+		return err
+	}
+
+	from, err := fields.point("from", calc.NewPoint(0, 0, 0))
+	if err != nil {
+		return err
+	}
+
+	to, err := fields.point("to", calc.NewPoint(0, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	up, err := fields.vector("up", calc.NewVector(0, 1, 0))
+	if err != nil {
+		return err
+	}
+
+	h, v := float64(width), float64(height)
+	view := scene.ViewTransform(from, to, up)
+
+	var camera interface {
+		scene.Projector
+		SetTransform(mat calc.Mat4x4) error
+	}
+
+	switch kind {
+	case "perspective":
+		aperture, err := fields.float("aperture", 0)
+		if err != nil {
+			return err
+		}
+
+		focalDistance, err := fields.float("focal-distance", 1)
+		if err != nil {
+			return err
+		}
+
+		c := scene.NewCamera(h, v, fov, scene.CameraAperture(aperture), scene.CameraFocalDistance(focalDistance))
+		camera = &c
+	case "orthographic":
+		viewWidth, err := fields.float("view-width", 2)
+		if err != nil {
+			return err
+		}
+
+		c := scene.NewOrthographicCamera(h, v, viewWidth)
+		camera = &c
+	case "fisheye":
+		c := scene.NewFisheyeCamera(h, v, fov)
+		camera = &c
+	case "equirectangular":
+		c := scene.NewEquirectangularCamera(h, v)
+		camera = &c
+	default:
+		typeNode, _ := fields.get("type")
+		return l.errorf(typeNode, "unknown camera type %q", kind)
+	}
+
+	if err := camera.SetTransform(view); err != nil {
+		return l.errorf(fields.node, "invalid camera view: %v", err)
+	}
+
+	l.camera = camera
+	return nil
+}
+
+func (o *Options) cameraSize(width, height int) (int, int) {
+	switch {
+	case o.Width > 0 && o.Height > 0:
+		return o.Width, o.Height
+	case o.Width > 0:
+		return o.Width, scaledSize(height, o.Width, width)
+	case o.Height > 0:
+		return scaledSize(width, o.Height, height), o.Height
+	default:
+		return width, height
+	}
+}
+
+//size*num/denを四捨五入、小さくしすぎても1pixelは残す
+func scaledSize(size, num, den int) int {
+	scaled := int(math.Round(float64(size) * float64(num) / float64(den)))
+	if scaled < 1 {
+		return 1
+	}
+
+	return scaled
+}
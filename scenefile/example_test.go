@@ -0,0 +1,59 @@
+package scenefile
+
+import (
+	"fmt"
+	"rayGo/calc"
+)
+
+//package docの例と同じscene
+const exampleScene = `
+- add: camera
+  width: 100
+  height: 50
+  field-of-view: 1.047
+  from: [0, 1.5, -5]
+  to: [0, 1, 0]
+  up: [0, 1, 0]
+- add: background
+  type: gradient
+  bottom: [1, 1, 1]
+  top: [0.5, 0.7, 1]
+- add: light
+  at: [-10, 10, -10]
+  intensity: [1, 1, 1]
+- define: glass
+  value:
+    transparency: 1
+    refractive-index: 1.5
+- add: sphere
+  material: glass
+  transform:
+    - [scale, 0.5, 0.5, 0.5]
+    - [translate, 0, 1, 0]
+`
+
+func ExampleParse() {
+	s, err := Parse([]byte(exampleScene), "example.yaml")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	width, height := s.Camera.CanvasSize()
+	fmt.Printf("canvas %dx%d\n", width, height)
+	fmt.Printf("%d light, %d object\n", len(s.World.Lights), len(s.World.Objects))
+
+	sphere := s.World.Objects[0]
+	m := sphere.GetMaterial()
+	fmt.Println("transparency", m.Transparency, "refractive index", m.RefractiveIndex)
+
+	//scaleしてからtranslateするので、中心は(0, 1, 0)で半径は0.5
+	top := sphere.GetTransform().MulByTuple(calc.NewPoint(0, 1, 0))
+	fmt.Printf("top of the sphere at y=%g\n", top[1])
+
+	// Output:
+	// canvas 100x50
+	// 1 light, 1 object
+	// transparency 1 refractive index 1.5
+	// top of the sphere at y=1.5
+}
@@ -0,0 +1,219 @@
+package scenefile
+
+import (
+	"math"
+	"rayGo/calc"
+	"rayGo/scene"
+
+	"gopkg.in/yaml.v3"
+)
+
+var white = scene.NewColor(1, 1, 1)
+
+func (l *loader) loadLight(kind string, fields *mapping) (scene.LightSource, error) {
+	switch kind {
+	case "light":
+		return l.pointLight(fields)
+	case "area-light":
+		return l.areaLight(fields)
+	case "sphere-light":
+		return l.sphereLight(fields)
+	case "spot-light":
+		return l.spotLight(fields)
+	default:
+		return l.directionalLight(fields)
+	}
+}
+
+func (l *loader) pointLight(fields *mapping) (scene.LightSource, error) {
+	if err := fields.allow("add", "at", "intensity", "attenuation"); err != nil {
+		return nil, err
+	}
+
+	at, err := fields.point("at", calc.NewPoint(0, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	intensity, err := fields.color("intensity", white)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := l.lightOptions(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewLight(at, intensity, options...), nil
+}
+
+func (l *loader) spotLight(fields *mapping) (scene.LightSource, error) {
+	if err := fields.allow("add", "at", "direction", "inner-angle", "outer-angle", "intensity", "attenuation"); err != nil {
+		return nil, err
+	}
+
+	at, err := fields.point("at", calc.NewPoint(0, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	direction, err := fields.vector("direction", calc.NewVector(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := fields.float("inner-angle", math.Pi/8)
+	if err != nil {
+		return nil, err
+	}
+
+	outer, err := fields.float("outer-angle", math.Pi/6)
+	if err != nil {
+		return nil, err
+	}
+
+	intensity, err := fields.color("intensity", white)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := l.lightOptions(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewSpotLight(at, direction, inner, outer, intensity, options...), nil
+}
+
+func (l *loader) directionalLight(fields *mapping) (scene.LightSource, error) {
+	if err := fields.allow("add", "direction", "intensity"); err != nil {
+		return nil, err
+	}
+
+	direction, err := fields.vector("direction", calc.NewVector(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	intensity, err := fields.color("intensity", white)
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewDirectionalLight(direction, intensity), nil
+}
+
+func (l *loader) areaLight(fields *mapping) (scene.LightSource, error) {
+	if err := fields.allow("add", "corner", "uvec", "usteps", "vvec", "vsteps", "intensity", "jitter", "seed"); err != nil {
+		return nil, err
+	}
+
+	corner, err := fields.point("corner", calc.NewPoint(0, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	uvec, err := fields.vector("uvec", calc.NewVector(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	usteps, err := fields.int("usteps", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	vvec, err := fields.vector("vvec", calc.NewVector(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	vsteps, err := fields.int("vsteps", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	intensity, err := fields.color("intensity", white)
+	if err != nil {
+		return nil, err
+	}
+
+	light := scene.NewAreaLight(corner, uvec, usteps, vvec, vsteps, intensity)
+	if light.Jitter, err = fields.bool("jitter", light.Jitter); err != nil {
+		return nil, err
+	}
+
+	seed, err := fields.int("seed", 0)
+	if err != nil {
+		return nil, err
+	}
+	light.Seed = uint64(seed)
+
+	return light, nil
+}
+
+func (l *loader) sphereLight(fields *mapping) (scene.LightSource, error) {
+	if err := fields.allow("add", "center", "radius", "samples", "intensity", "jitter", "seed"); err != nil {
+		return nil, err
+	}
+
+	center, err := fields.point("center", calc.NewPoint(0, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	radius, err := fields.float("radius", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := fields.int("samples", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	intensity, err := fields.color("intensity", white)
+	if err != nil {
+		return nil, err
+	}
+
+	light := scene.NewSphereLight(center, radius, samples, intensity)
+	if light.Jitter, err = fields.bool("jitter", light.Jitter); err != nil {
+		return nil, err
+	}
+
+	seed, err := fields.int("seed", 0)
+	if err != nil {
+		return nil, err
+	}
+	light.Seed = uint64(seed)
+
+	return light, nil
+}
+
+//attenuationは[constant, linear, quadratic]かinverse-square
+func (l *loader) lightOptions(fields *mapping) ([]scene.LightOption, error) {
+	node, ok := fields.get("attenuation")
+	if !ok {
+		return nil, nil
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		switch node.Value {
+		case "none":
+			return []scene.LightOption{scene.LightAttenuation(scene.NoAttenuation)}, nil
+		case "inverse-square":
+			return []scene.LightOption{scene.LightAttenuation(scene.InverseSquareAttenuation)}, nil
+		}
+
+		return nil, l.errorf(node, "unknown attenuation %q", node.Value)
+	}
+
+	v, err := l.floats(node, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return []scene.LightOption{scene.LightAttenuation(scene.NewAttenuation(v[0], v[1], v[2]))}, nil
+}
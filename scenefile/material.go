@@ -0,0 +1,268 @@
+package scenefile
+
+import (
+	"rayGo/scene"
+
+	"gopkg.in/yaml.v3"
+)
+
+//materialはmappingかdefineしたmaterialの名前
+func (l *loader) material(node *yaml.Node) (*scene.Material, error) {
+	node, err := l.resolve(node)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := l.mapping(node)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fields.allow("color", "ambient", "diffuse", "specular", "shininess",
+		"reflective", "transparency", "refractive-index", "pattern"); err != nil {
+		return nil, err
+	}
+
+	m := scene.DefaultMaterial()
+	if m.Color, err = fields.color("color", m.Color); err != nil {
+		return nil, err
+	}
+
+	for _, f := range []struct {
+		key   string
+		value *float64
+	}{
+		{"ambient", &m.Ambient},
+		{"diffuse", &m.Diffuse},
+		{"specular", &m.Specular},
+		{"shininess", &m.Shininess},
+		{"reflective", &m.Reflective},
+		{"transparency", &m.Transparency},
+		{"refractive-index", &m.RefractiveIndex},
+	} {
+		if *f.value, err = fields.float(f.key, *f.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if patternNode, ok := fields.get("pattern"); ok {
+		pattern, err := l.pattern(patternNode)
+		if err != nil {
+			return nil, err
+		}
+
+		m.SetPattern(pattern)
+	}
+
+	return m, nil
+}
+
+func (l *loader) pattern(node *yaml.Node) (scene.Pattern, error) {
+	node, err := l.resolve(node)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := l.mapping(node)
+	if err != nil {
+		return nil, err
+	}
+
+	typeNode, err := fields.require("type")
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := l.string(typeNode)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "map" {
+		return l.mapPattern(fields)
+	}
+
+	if err := fields.allow("type", "colors", "transform"); err != nil {
+		return nil, err
+	}
+
+	c1, c2, err := l.colorPair(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern scene.Pattern
+	switch kind {
+	case "stripes":
+		pattern = scene.NewStripePattern(c1, c2)
+	case "gradient":
+		pattern = scene.NewGradientPattern(c1, c2)
+	case "rings":
+		pattern = scene.NewRingPattern(c1, c2)
+	case "checkers":
+		pattern = scene.NewCheckersPattern(c1, c2)
+	default:
+		return nil, l.errorf(typeNode, "unknown pattern type %q", kind)
+	}
+
+	return l.patternTransform(pattern, fields)
+}
+
+func (l *loader) patternTransform(pattern scene.Pattern, fields *mapping) (scene.Pattern, error) {
+	if transformNode, ok := fields.get("transform"); ok {
+		mat, err := l.transform(transformNode)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := pattern.SetTransform(mat); err != nil {
+			return nil, l.errorf(transformNode, "%v", err)
+		}
+	}
+
+	return pattern, nil
+}
+
+var uvMappings = map[string]scene.UVMapping{
+	"spherical":   scene.SphericalMap,
+	"planar":      scene.PlanarMap,
+	"cylindrical": scene.CylindricalMap,
+	"conical":     scene.ConicalMap,
+	"cube":        scene.CubicMap,
+}
+
+var cubeFaceKeys = []string{"left", "right", "front", "back", "up", "down"}
+
+//type: mapはuv-patternをmappingで貼る、mappingを省くと当たったshapeに合わせる
+//mapping: cubeでは面ごとにleft,right,front,back,up,downを書いてもよい
+func (l *loader) mapPattern(fields *mapping) (scene.Pattern, error) {
+	if err := fields.allow(append([]string{"type", "mapping", "uv-pattern", "transform"}, cubeFaceKeys...)...); err != nil {
+		return nil, err
+	}
+
+	var uvMapping scene.UVMapping
+	mappingName := ""
+	if mappingNode, ok := fields.get("mapping"); ok {
+		var err error
+		if mappingName, err = l.string(mappingNode); err != nil {
+			return nil, err
+		}
+
+		if uvMapping, ok = uvMappings[mappingName]; !ok {
+			return nil, l.errorf(mappingNode, "unknown mapping %q", mappingName)
+		}
+	}
+
+	hasFaces := false
+	for _, key := range cubeFaceKeys {
+		if _, ok := fields.get(key); ok {
+			hasFaces = true
+		}
+	}
+
+	if hasFaces {
+		if mappingName != "cube" {
+			return nil, l.errorf(fields.node, "faces can only be given with mapping: cube")
+		}
+
+		var faces [6]scene.UVPattern
+		for i, key := range cubeFaceKeys {
+			faceNode, err := fields.require(key)
+			if err != nil {
+				return nil, err
+			}
+
+			if faces[i], err = l.uvPattern(faceNode); err != nil {
+				return nil, err
+			}
+		}
+
+		return l.patternTransform(scene.NewCubeMapPattern(faces[0], faces[1], faces[2], faces[3], faces[4], faces[5]), fields)
+	}
+
+	uvNode, err := fields.require("uv-pattern")
+	if err != nil {
+		return nil, err
+	}
+
+	uvPattern, err := l.uvPattern(uvNode)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.patternTransform(scene.NewTextureMapPattern(uvPattern, uvMapping), fields)
+}
+
+func (l *loader) uvPattern(node *yaml.Node) (scene.UVPattern, error) {
+	node, err := l.resolve(node)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := l.mapping(node)
+	if err != nil {
+		return nil, err
+	}
+
+	typeNode, err := fields.require("type")
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := l.string(typeNode)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case "checkers":
+	case "image":
+		return l.imageTexture(fields)
+	default:
+		return nil, l.errorf(typeNode, "unknown uv pattern type %q", kind)
+	}
+
+	if err := fields.allow("type", "width", "height", "colors"); err != nil {
+		return nil, err
+	}
+
+	width, err := fields.float("width", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := fields.float("height", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	c1, c2, err := l.colorPair(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewUVCheckers(width, height, c1, c2), nil
+}
+
+func (l *loader) colorPair(fields *mapping) (scene.Color, scene.Color, error) {
+	colorsNode, err := fields.require("colors")
+	if err != nil {
+		return scene.Color{}, scene.Color{}, err
+	}
+
+	if colorsNode.Kind != yaml.SequenceNode || len(colorsNode.Content) != 2 {
+		return scene.Color{}, scene.Color{}, l.errorf(colorsNode, "pattern needs a list of 2 colors")
+	}
+
+	c1, err := l.color(colorsNode.Content[0])
+	if err != nil {
+		return scene.Color{}, scene.Color{}, err
+	}
+
+	c2, err := l.color(colorsNode.Content[1])
+	if err != nil {
+		return scene.Color{}, scene.Color{}, err
+	}
+
+	return c1, c2, nil
+}
@@ -0,0 +1,242 @@
+package scenefile
+
+import (
+	"rayGo/calc"
+	"rayGo/scene"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//yamlのmappingをkeyで引けるようにしたもの、keyの行番号もerrorに使う
+type mapping struct {
+	l      *loader
+	node   *yaml.Node
+	keys   map[string]*yaml.Node
+	values map[string]*yaml.Node
+}
+
+func (l *loader) mapping(node *yaml.Node) (*mapping, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, l.errorf(node, "expected a mapping")
+	}
+
+	m := &mapping{
+		l:      l,
+		node:   node,
+		keys:   make(map[string]*yaml.Node),
+		values: make(map[string]*yaml.Node),
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if _, ok := m.keys[key.Value]; ok {
+			return nil, l.errorf(key, "duplicate key %q", key.Value)
+		}
+
+		m.keys[key.Value] = key
+		m.values[key.Value] = node.Content[i+1]
+	}
+
+	return m, nil
+}
+
+func (m *mapping) get(key string) (*yaml.Node, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+//知らないkeyはtypoのことが多いのでerrorにする
+func (m *mapping) allow(keys ...string) error {
+	allowed := make(map[string]bool)
+	for _, key := range keys {
+		allowed[key] = true
+	}
+
+	var unknown []string
+	for key := range m.keys {
+		if !allowed[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	//何度読んでも同じerrorになるように一番上のkeyを報告する
+	sort.Slice(unknown, func(i, j int) bool {
+		return m.keys[unknown[i]].Line < m.keys[unknown[j]].Line
+	})
+
+	return m.l.errorf(m.keys[unknown[0]], "unknown key %q", unknown[0])
+}
+
+func (m *mapping) float(key string, defaultValue float64) (float64, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.float(node)
+}
+
+func (m *mapping) int(key string, defaultValue int) (int, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.int(node)
+}
+
+func (m *mapping) bool(key string, defaultValue bool) (bool, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.bool(node)
+}
+
+func (m *mapping) point(key string, defaultValue calc.Tuple4) (calc.Tuple4, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.point(node)
+}
+
+func (m *mapping) vector(key string, defaultValue calc.Tuple4) (calc.Tuple4, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.vector(node)
+}
+
+func (m *mapping) color(key string, defaultValue scene.Color) (scene.Color, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	return m.l.color(node)
+}
+
+//必須の項目
+func (m *mapping) require(key string) (*yaml.Node, error) {
+	node, ok := m.get(key)
+	if !ok {
+		return nil, m.l.errorf(m.node, "missing %q", key)
+	}
+
+	return node, nil
+}
+
+func (l *loader) scalar(node *yaml.Node, kind string) error {
+	if node.Kind != yaml.ScalarNode {
+		return l.errorf(node, "expected %s", kind)
+	}
+
+	return nil
+}
+
+func (l *loader) string(node *yaml.Node) (string, error) {
+	if err := l.scalar(node, "a string"); err != nil {
+		return "", err
+	}
+
+	return node.Value, nil
+}
+
+func (l *loader) float(node *yaml.Node) (float64, error) {
+	if err := l.scalar(node, "a number"); err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return 0, l.errorf(node, "expected a number, got %q", node.Value)
+	}
+
+	return value, nil
+}
+
+func (l *loader) int(node *yaml.Node) (int, error) {
+	if err := l.scalar(node, "an integer"); err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(node.Value)
+	if err != nil {
+		return 0, l.errorf(node, "expected an integer, got %q", node.Value)
+	}
+
+	return value, nil
+}
+
+func (l *loader) bool(node *yaml.Node) (bool, error) {
+	if err := l.scalar(node, "true or false"); err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(node.Value) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	}
+
+	return false, l.errorf(node, "expected true or false, got %q", node.Value)
+}
+
+//[x, y, z]のような数のlist
+func (l *loader) floats(node *yaml.Node, n int) ([]float64, error) {
+	if node.Kind != yaml.SequenceNode || len(node.Content) != n {
+		return nil, l.errorf(node, "expected a list of %d numbers", n)
+	}
+
+	values := make([]float64, n)
+	for i, child := range node.Content {
+		value, err := l.float(child)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func (l *loader) point(node *yaml.Node) (calc.Tuple4, error) {
+	v, err := l.floats(node, 3)
+	if err != nil {
+		return calc.Tuple4{}, err
+	}
+
+	return calc.NewPoint(v[0], v[1], v[2]), nil
+}
+
+func (l *loader) vector(node *yaml.Node) (calc.Tuple4, error) {
+	v, err := l.floats(node, 3)
+	if err != nil {
+		return calc.Tuple4{}, err
+	}
+
+	return calc.NewVector(v[0], v[1], v[2]), nil
+}
+
+func (l *loader) color(node *yaml.Node) (scene.Color, error) {
+	v, err := l.floats(node, 3)
+	if err != nil {
+		return scene.Color{}, err
+	}
+
+	return scene.NewColor(v[0], v[1], v[2]), nil
+}
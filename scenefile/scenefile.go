@@ -0,0 +1,319 @@
+//yamlで書いたsceneを読み込んでWorldとCameraを作る
+//
+//fileはitemのlistで、各itemはadd(cameraやlight、shapeを追加する)かdefine(名前をつけて使い回す)
+//
+//   - add: camera
+//     width: 100
+//     height: 50
+//     field-of-view: 1.047
+//     from: [0, 1.5, -5]
+//     to: [0, 1, 0]
+//     up: [0, 1, 0]
+//   - add: background
+//     type: gradient
+//     bottom: [1, 1, 1]
+//     top: [0.5, 0.7, 1]
+//   - add: light
+//     at: [-10, 10, -10]
+//     intensity: [1, 1, 1]
+//   - define: glass
+//     value:
+//       transparency: 1
+//       refractive-index: 1.5
+//   - add: sphere
+//     material: glass
+//     transform:
+//       - [scale, 0.5, 0.5, 0.5]
+//       - [translate, 0, 1, 0]
+//
+//transformは上から順に適用される
+package scenefile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"rayGo/scene"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Scene struct {
+	World  *scene.World
+	Camera scene.Projector
+	//backgroundなどfileに書いたrenderの設定、Render(RenderWithSettings(s.Settings))のように渡す
+	Settings scene.RenderSettings
+}
+
+//どのfileの何行目で何が起きたか
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+type Options struct {
+	Width  int
+	Height int
+}
+
+type Option func(*Options)
+
+//fileに書いたcameraの大きさを上書きする、片方だけなら縦横比を保つ
+func CameraWidth(width int) Option {
+	return func(o *Options) {
+		o.Width = width
+	}
+}
+
+func CameraHeight(height int) Option {
+	return func(o *Options) {
+		o.Height = height
+	}
+}
+
+func newOptions(options ...Option) *Options {
+	defaultOptions := &Options{}
+
+	for _, fn := range options {
+		fn(defaultOptions)
+	}
+
+	return defaultOptions
+}
+
+func LoadFile(path string, options ...Option) (*Scene, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data, path, options...)
+}
+
+//yamlのerrorは"yaml: line 3: ..."の形なので行番号をErrorに移す
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): `)
+
+//pathはerrorの表示と、objなどのfileをpathのdirectoryからの相対pathで探すのに使う
+func Parse(data []byte, path string, options ...Option) (*Scene, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		sceneErr := Error{
+			File:    path,
+			Message: err.Error(),
+		}
+
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			sceneErr.Line, _ = strconv.Atoi(match[1])
+			sceneErr.Message = err.Error()[len(match[0]):]
+		}
+
+		return nil, sceneErr
+	}
+
+	l := &loader{
+		file:     path,
+		baseDir:  filepath.Dir(path),
+		defines:  make(map[string]*yaml.Node),
+		images:   make(map[string]*scene.Canvas),
+		world:    &scene.World{},
+		settings: *scene.DefaultRenderSettings(),
+		options:  newOptions(options...),
+	}
+
+	if err := l.load(&doc); err != nil {
+		return nil, err
+	}
+
+	return &Scene{
+		World:    l.world,
+		Camera:   l.camera,
+		Settings: l.settings,
+	}, nil
+}
+
+type loader struct {
+	file     string
+	baseDir  string
+	defines  map[string]*yaml.Node
+	images   map[string]*scene.Canvas
+	world    *scene.World
+	camera   scene.Projector
+	settings scene.RenderSettings
+	//backgroundは一つしか書けない
+	hasBackground bool
+	options       *Options
+}
+
+func (l *loader) errorf(node *yaml.Node, format string, args ...interface{}) error {
+	return Error{
+		File:    l.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (l *loader) load(doc *yaml.Node) error {
+	//空のfile
+	if len(doc.Content) == 0 {
+		return Error{File: l.file, Message: "scene has no camera"}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return l.errorf(root, "scene must be a list of items")
+	}
+
+	for _, item := range root.Content {
+		if err := l.loadItem(item); err != nil {
+			return err
+		}
+	}
+
+	if l.camera == nil {
+		return l.errorf(root, "scene has no camera")
+	}
+
+	return nil
+}
+
+func (l *loader) loadItem(item *yaml.Node) error {
+	fields, err := l.mapping(item)
+	if err != nil {
+		return err
+	}
+
+	if define, ok := fields.get("define"); ok {
+		return l.loadDefine(define, fields)
+	}
+
+	add, ok := fields.get("add")
+	if !ok {
+		return l.errorf(item, "item must have either add or define")
+	}
+
+	kind, err := l.string(add)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "camera":
+		return l.loadCamera(fields)
+	case "background":
+		return l.loadBackground(fields)
+	case "light", "area-light", "sphere-light", "spot-light", "directional-light":
+		light, err := l.loadLight(kind, fields)
+		if err != nil {
+			return err
+		}
+
+		l.world.AddLights(light)
+		return nil
+	default:
+		shape, err := l.loadShape(item)
+		if err != nil {
+			return err
+		}
+
+		l.world.AddObjects(shape)
+		return nil
+	}
+}
+
+//define: name, value: ..., extend: other
+//extendしたときはvalueのmappingの項目でotherの項目を上書きする
+func (l *loader) loadDefine(define *yaml.Node, fields *mapping) error {
+	if err := fields.allow("define", "value", "extend"); err != nil {
+		return err
+	}
+
+	name, err := l.string(define)
+	if err != nil {
+		return err
+	}
+
+	value, ok := fields.get("value")
+	if !ok {
+		return l.errorf(fields.node, "define %q has no value", name)
+	}
+
+	if extend, ok := fields.get("extend"); ok {
+		baseName, err := l.string(extend)
+		if err != nil {
+			return err
+		}
+
+		base, ok := l.defines[baseName]
+		if !ok {
+			return l.errorf(extend, "undefined name %q", baseName)
+		}
+
+		value, err = l.merge(base, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.defines[name] = value
+	return nil
+}
+
+//baseの項目をoverrideの項目で上書きした新しいmapping
+func (l *loader) merge(base, override *yaml.Node) (*yaml.Node, error) {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return nil, l.errorf(override, "only mappings can be extended")
+	}
+
+	merged := &yaml.Node{
+		Kind:   yaml.MappingNode,
+		Tag:    override.Tag,
+		Line:   override.Line,
+		Column: override.Column,
+	}
+
+	overridden := make(map[string]bool)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		overridden[override.Content[i].Value] = true
+	}
+
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		if !overridden[base.Content[i].Value] {
+			merged.Content = append(merged.Content, base.Content[i], base.Content[i+1])
+		}
+	}
+
+	merged.Content = append(merged.Content, override.Content...)
+
+	return merged, nil
+}
+
+//文字列ならdefineした名前として引く
+func (l *loader) resolve(node *yaml.Node) (*yaml.Node, error) {
+	if node.Kind != yaml.ScalarNode {
+		return node, nil
+	}
+
+	value, ok := l.defines[node.Value]
+	if !ok {
+		return nil, l.errorf(node, "undefined name %q", node.Value)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,654 @@
+package scenefile
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"rayGo/calc"
+	"rayGo/scene"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const cameraItem = `
+- add: camera
+  width: 100
+  height: 50
+  field-of-view: 0.785
+  from: [-6, 6, -10]
+  to: [6, 0, 6]
+  up: [-0.45, 1, 0]
+`
+
+func Test_Parse_Camera(t *testing.T) {
+	s, err := Parse([]byte(cameraItem), "test.yaml")
+	require.Nil(t, err)
+
+	camera, ok := s.Camera.(*scene.Camera)
+	require.True(t, ok)
+	require.Equal(t, 100.0, camera.HSize)
+	require.Equal(t, 50.0, camera.VSize)
+	require.Equal(t, 0.785, camera.FieldOfView)
+	require.Equal(t, scene.ViewTransform(calc.NewPoint(-6, 6, -10), calc.NewPoint(6, 0, 6), calc.NewVector(-0.45, 1, 0)), camera.Transform)
+
+	width, height := s.Camera.CanvasSize()
+	require.Equal(t, 100, width)
+	require.Equal(t, 50, height)
+}
+
+func Test_Parse_Camera_Types(t *testing.T) {
+	for _, target := range []struct {
+		kind string
+		ans  scene.Projector
+	}{
+		{"orthographic", &scene.OrthographicCamera{}},
+		{"fisheye", &scene.FisheyeCamera{}},
+		{"equirectangular", &scene.EquirectangularCamera{}},
+	} {
+		s, err := Parse([]byte("- add: camera\n  type: "+target.kind+"\n"), "test.yaml")
+		require.Nil(t, err, target.kind)
+		require.IsType(t, target.ans, s.Camera, target.kind)
+	}
+}
+
+func Test_Parse_Lights(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- add: light
+  at: [50, 100, -50]
+  intensity: [1, 1, 1]
+- add: spot-light
+  at: [0, 10, 0]
+  direction: [0, -1, 0]
+  attenuation: inverse-square
+- add: directional-light
+  direction: [0, -1, 0]
+  intensity: [0.2, 0.2, 0.2]
+- add: area-light
+  corner: [-1, 2, 4]
+  uvec: [2, 0, 0]
+  usteps: 4
+  vvec: [0, 2, 0]
+  vsteps: 2
+  jitter: false
+- add: sphere-light
+  center: [0, 5, 0]
+  radius: 0.5
+  samples: 8
+`), "test.yaml")
+	require.Nil(t, err)
+	require.Len(t, s.World.Lights, 5)
+
+	require.Equal(t, scene.NewLight(calc.NewPoint(50, 100, -50), scene.NewColor(1, 1, 1)), s.World.Lights[0])
+	require.Equal(t, scene.InverseSquareAttenuation, s.World.Lights[1].(scene.SpotLight).Attenuation)
+	require.Equal(t, scene.NewColor(0.2, 0.2, 0.2), s.World.Lights[2].(scene.DirectionalLight).Intensity)
+
+	area := s.World.Lights[3].(scene.AreaLight)
+	require.Equal(t, 8, area.SampleCount())
+	require.False(t, area.Jitter)
+	require.Equal(t, calc.NewPoint(0, 3, 4), area.Position)
+
+	require.Equal(t, 8, s.World.Lights[4].(scene.SphereLight).Samples)
+}
+
+func Test_Parse_Shape_Material_And_Transform(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- add: sphere
+  material:
+    color: [1, 0.2, 1]
+    diffuse: 0.7
+    pattern:
+      type: stripes
+      colors:
+        - [1, 1, 1]
+        - [0, 0, 0]
+  transform:
+    - [scale, 2, 2, 2]
+    - [translate, 0, 1, 0]
+`), "test.yaml")
+	require.Nil(t, err)
+	require.Len(t, s.World.Objects, 1)
+
+	sphere := s.World.Objects[0]
+	require.Equal(t, scene.NewColor(1, 0.2, 1), sphere.GetMaterial().Color)
+	require.Equal(t, 0.7, sphere.GetMaterial().Diffuse)
+	require.Equal(t, 0.9, sphere.GetMaterial().Specular)
+	require.IsType(t, scene.StripePattern{}, sphere.GetMaterial().Pattern)
+
+	//scaleしてからtranslate
+	require.Equal(t, calc.MulMatMulti(calc.NewTranslation(0, 1, 0), calc.NewScale(2, 2, 2)), sphere.GetTransform())
+}
+
+func Test_Parse_Transforms(t *testing.T) {
+	for _, target := range []struct {
+		transform string
+		ans       calc.Mat4x4
+	}{
+		{"[[translate, 1, 2, 3]]", calc.NewTranslation(1, 2, 3)},
+		{"[[rotate-x, 1.5]]", calc.NewRotateX(1.5)},
+		{"[[rotate-y, 1.5]]", calc.NewRotateY(1.5)},
+		{"[[rotate-z, 1.5]]", calc.NewRotateZ(1.5)},
+		{"[[shear, 1, 0, 0, 0, 0, 1]]", calc.NewShearing(1, 0, 0, 0, 0, 1)},
+		{"[[rotate-x, 1.5], [scale, 5, 5, 5], [translate, 10, 5, 7]]", calc.MulMatMulti(calc.NewTranslation(10, 5, 7), calc.NewScale(5, 5, 5), calc.NewRotateX(1.5))},
+	} {
+		s, err := Parse([]byte(cameraItem+"- add: cube\n  transform: "+target.transform+"\n"), "test.yaml")
+		require.Nil(t, err, target.transform)
+		require.Equal(t, target.ans, s.World.Objects[0].GetTransform(), target.transform)
+	}
+}
+
+func Test_Parse_Transform_Cycles(t *testing.T) {
+	for _, target := range []struct {
+		yaml string
+		ans  string
+	}{
+		{"- define: t\n  value: [t]\n- add: cube\n  transform: t\n", "test.yaml:10:11: transform \"t\" refers to itself"},
+		{"- define: a\n  value: [b]\n- define: b\n  value: [[scale, 2, 2, 2], a]\n- add: cube\n  transform: [a]\n", "test.yaml:12:29: transform \"a\" refers to itself"},
+	} {
+		_, err := Parse([]byte(cameraItem+target.yaml), "test.yaml")
+		require.NotNil(t, err, target.yaml)
+		require.Equal(t, target.ans, err.Error(), target.yaml)
+	}
+
+	//同じdefineを何度使っても循環ではない
+	s, err := Parse([]byte(cameraItem+"- define: a\n  value: [[scale, 2, 2, 2]]\n- define: b\n  value: [a, a]\n- add: cube\n  transform: [b, a]\n"), "test.yaml")
+	require.Nil(t, err)
+	require.Equal(t, calc.NewScale(8, 8, 8), s.World.Objects[0].GetTransform())
+}
+
+func Test_Parse_Defines(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- define: white-material
+  value:
+    color: [1, 1, 1]
+    diffuse: 0.7
+    reflective: 0.1
+- define: blue-material
+  extend: white-material
+  value:
+    color: [0.537, 0.831, 0.914]
+- define: standard-transform
+  value:
+    - [translate, 1, -1, 1]
+    - [scale, 0.5, 0.5, 0.5]
+- define: large-object
+  value:
+    - standard-transform
+    - [scale, 3.5, 3.5, 3.5]
+- add: cube
+  material: blue-material
+  transform:
+    - large-object
+    - [translate, 4, 0, 0]
+`), "test.yaml")
+	require.Nil(t, err)
+
+	cube := s.World.Objects[0]
+	require.Equal(t, scene.NewColor(0.537, 0.831, 0.914), cube.GetMaterial().Color)
+	require.Equal(t, 0.7, cube.GetMaterial().Diffuse)
+	require.Equal(t, 0.1, cube.GetMaterial().Reflective)
+	require.Equal(t, calc.MulMatMulti(
+		calc.NewTranslation(4, 0, 0),
+		calc.NewScale(3.5, 3.5, 3.5),
+		calc.NewScale(0.5, 0.5, 0.5),
+		calc.NewTranslation(1, -1, 1),
+	), cube.GetTransform())
+}
+
+func Test_Parse_Defined_Shape(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- define: pillar
+  value:
+    add: cylinder
+    min: 0
+    max: 3
+    closed: true
+    material:
+      color: [1, 0, 0]
+- add: pillar
+  transform:
+    - [translate, 2, 0, 0]
+`), "test.yaml")
+	require.Nil(t, err)
+
+	pillar, ok := s.World.Objects[0].(scene.Cyliner)
+	require.True(t, ok)
+	require.Equal(t, 0.0, pillar.Min)
+	require.Equal(t, 3.0, pillar.Max)
+	require.True(t, pillar.Closed)
+	require.Equal(t, scene.NewColor(1, 0, 0), pillar.GetMaterial().Color)
+	require.Equal(t, calc.NewTranslation(2, 0, 0), pillar.GetTransform())
+}
+
+func Test_Parse_Group_And_CSG(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- add: group
+  material:
+    color: [0, 1, 0]
+  children:
+    - add: sphere
+    - add: cone
+      min: -1
+      max: 0
+      material:
+        color: [0, 0, 1]
+    - add: csg
+      operation: difference
+      left:
+        add: cube
+      right:
+        add: sphere
+        transform:
+          - [scale, 1.2, 1.2, 1.2]
+`), "test.yaml")
+	require.Nil(t, err)
+
+	group := s.World.Objects[0].(*scene.Group)
+	require.Len(t, group.Children, 3)
+	require.Equal(t, scene.NewColor(0, 1, 0), group.Children[0].GetMaterial().Color)
+	require.Equal(t, scene.NewColor(0, 0, 1), group.Children[1].GetMaterial().Color)
+	require.Equal(t, group, group.Children[0].GetParent())
+
+	csg := group.Children[2].(*scene.CSG)
+	require.Equal(t, scene.CSGDifference, csg.Operation)
+	require.Equal(t, scene.NewColor(0, 1, 0), csg.Left.GetMaterial().Color)
+	require.Equal(t, scene.NewColor(0, 1, 0), csg.Right.GetMaterial().Color)
+	require.Equal(t, calc.NewScale(1.2, 1.2, 1.2), csg.Right.GetTransform())
+}
+
+func Test_Parse_Obj(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenefile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	obj := "v -1 1 0\nv -1 0 0\nv 1 0 0\nv 1 1 0\n\nf 1 2 3\nf 1 3 4\n"
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "model.obj"), []byte(obj), 0644))
+
+	scenePath := filepath.Join(dir, "scene.yaml")
+	require.Nil(t, ioutil.WriteFile(scenePath, []byte(cameraItem+`
+- add: obj
+  file: model.obj
+  material:
+    color: [1, 0, 0]
+`), 0644))
+
+	s, err := LoadFile(scenePath)
+	require.Nil(t, err)
+
+	group := s.World.Objects[0].(*scene.Group)
+	var triangles []scene.Shape
+	var collect func(g *scene.Group)
+	collect = func(g *scene.Group) {
+		for _, child := range g.Children {
+			if childGroup, ok := child.(*scene.Group); ok {
+				collect(childGroup)
+			} else {
+				triangles = append(triangles, child)
+			}
+		}
+	}
+	collect(group)
+
+	require.Len(t, triangles, 2)
+	for _, triangle := range triangles {
+		require.Equal(t, scene.NewColor(1, 0, 0), triangle.GetMaterial().Color)
+	}
+}
+
+func Test_Parse_Obj_Mtl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenefile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	//mtllibはscene fileではなくobj fileのdirectoryから探す
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "models"), 0755))
+	obj := "mtllib model.mtl\nv -1 1 0\nv -1 0 0\nv 1 0 0\n\nusemtl blue\nf 1 2 3\n"
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "models", "model.obj"), []byte(obj), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "models", "model.mtl"), []byte("newmtl blue\nKd 0 0 1\n"), 0644))
+
+	for _, target := range []struct {
+		material string
+		expected scene.Color
+	}{
+		{"", scene.NewColor(0, 0, 1)},
+		{"  material:\n    color: [1, 0, 0]\n", scene.NewColor(1, 0, 0)},
+	} {
+		s, err := Parse([]byte(cameraItem+"- add: obj\n  file: models/model.obj\n"+target.material), filepath.Join(dir, "scene.yaml"))
+		require.Nil(t, err)
+
+		triangle := s.World.Objects[0].(*scene.Group).Children[0].(*scene.Group).Children[0]
+		require.Equal(t, target.expected, triangle.GetMaterial().Color)
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	for _, target := range []struct {
+		yaml string
+		ans  string
+	}{
+		{"", "test.yaml: scene has no camera"},
+		{"- add: sphere\n", "test.yaml:1:1: scene has no camera"},
+		{cameraItem + "- add: teapot\n", "test.yaml:9:8: unknown shape \"teapot\""},
+		{cameraItem + "- add: sphere\n  materal: {}\n", "test.yaml:10:3: unknown key \"materal\""},
+		{cameraItem + "- add: sphere\n  material: missing\n", "test.yaml:10:13: undefined name \"missing\""},
+		{cameraItem + "- add: sphere\n  transform:\n    - [translate, 1, 2]\n", "test.yaml:11:7: translate takes 3 numbers, got 2"},
+		{cameraItem + "- add: sphere\n  transform:\n    - [spin, 1]\n", "test.yaml:11:8: unknown transform \"spin\""},
+		{cameraItem + "- add: light\n  at: [1, a, 3]\n", "test.yaml:10:11: expected a number, got \"a\""},
+		{cameraItem + "- add: csg\n  operation: xor\n  left: {add: cube}\n  right: {add: cube}\n", "test.yaml:10:14: unknown csg operation \"xor\""},
+		{cameraItem + "- add: obj\n  file: missing.obj\n", "test.yaml:10:9: open missing.obj: no such file or directory"},
+		{cameraItem + "- add: camera\n", "test.yaml:9:3: scene has more than one camera"},
+		{"- add: camera\n  width: [1\n", "test.yaml:1: did not find expected ',' or ']'"},
+	} {
+		_, err := Parse([]byte(target.yaml), "test.yaml")
+		require.NotNil(t, err, target.yaml)
+		require.IsType(t, Error{}, err, target.yaml)
+		require.Equal(t, target.ans, err.Error(), target.yaml)
+	}
+}
+
+func Test_Parse_Malformed_YAML_Does_Not_Panic(t *testing.T) {
+	//古いyaml.v3はこの入力でpanicしていた(CVE-2022-28948)
+	for _, data := range []string{
+		"0: [:!00 \xef",
+		"- add: camera\n  width: !!binary \xff\n",
+	} {
+		require.NotPanics(t, func() {
+			_, err := Parse([]byte(data), "test.yaml")
+			require.NotNil(t, err, data)
+			require.IsType(t, Error{}, err, data)
+		}, data)
+	}
+}
+
+func Test_Parse_Obj_Error_Reports_Obj_Line(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenefile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "broken.obj"), []byte("v 1 0 0\nv 1 x 0\n"), 0644))
+
+	_, err = Parse([]byte(cameraItem+"- add: obj\n  file: broken.obj\n"), filepath.Join(dir, "scene.yaml"))
+	require.NotNil(t, err)
+
+	require.Equal(t, Error{
+		File:    filepath.Join(dir, "broken.obj"),
+		Line:    2,
+		Column:  5,
+		Message: "invalid number: \"x\"",
+	}, err)
+
+	//mtlのエラーはmtl fileの位置で報告する
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "model.obj"), []byte("mtllib broken.mtl\n"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "broken.mtl"), []byte("newmtl red\nKd\t1 x 0\n"), 0644))
+
+	_, err = Parse([]byte(cameraItem+"- add: obj\n  file: model.obj\n"), filepath.Join(dir, "scene.yaml"))
+	require.Equal(t, filepath.Join(dir, "broken.mtl")+":2:6: invalid number: \"x\"", err.Error())
+}
+
+func Test_Parse_Renders(t *testing.T) {
+	s, err := Parse([]byte(`
+- add: camera
+  width: 11
+  height: 11
+  field-of-view: 1.5707963267948966
+  from: [0, 0, -5]
+  to: [0, 0, 0]
+- add: light
+  at: [-10, 10, -10]
+- add: sphere
+  material:
+    color: [0.8, 1.0, 0.6]
+    diffuse: 0.7
+    specular: 0.2
+`), "test.yaml")
+	require.Nil(t, err)
+
+	canvas, err := s.World.Render(s.Camera)
+	require.Nil(t, err)
+
+	c := canvas.Pixels[5][5]
+	require.True(t, math.Abs(c.Red-0.38066) < 0.0001, c)
+	require.True(t, math.Abs(c.Green-0.47583) < 0.0001, c)
+	require.True(t, math.Abs(c.Blue-0.2855) < 0.0001, c)
+}
+
+func Test_Parse_Camera_Size_Options(t *testing.T) {
+	for _, target := range []struct {
+		options []Option
+		width   int
+		height  int
+	}{
+		{nil, 100, 50},
+		{[]Option{CameraWidth(300), CameraHeight(300)}, 300, 300},
+		{[]Option{CameraWidth(300)}, 300, 150},
+		{[]Option{CameraHeight(10)}, 20, 10},
+		{[]Option{CameraWidth(1)}, 1, 1},
+	} {
+		s, err := Parse([]byte(cameraItem), "test.yaml", target.options...)
+		require.Nil(t, err)
+
+		width, height := s.Camera.CanvasSize()
+		require.Equal(t, target.width, width, target)
+		require.Equal(t, target.height, height, target)
+	}
+}
+
+func Test_Parse_Background(t *testing.T) {
+	s, err := Parse([]byte(cameraItem), "test.yaml")
+	require.Nil(t, err)
+	require.Equal(t, *scene.DefaultRenderSettings(), s.Settings)
+
+	for _, target := range []struct {
+		yaml string
+		ans  scene.Background
+	}{
+		{"- add: background\n  type: solid\n  color: [0.1, 0.2, 0.3]\n", scene.NewSolidBackground(scene.NewColor(0.1, 0.2, 0.3))},
+		{"- add: background\n  type: gradient\n  bottom: [1, 1, 1]\n  top: [0, 0, 1]\n", scene.NewGradientBackground(scene.White, scene.Blue)},
+	} {
+		s, err := Parse([]byte(cameraItem+target.yaml), "test.yaml")
+		require.Nil(t, err, target.yaml)
+		require.Equal(t, target.ans, s.Settings.Background, target.yaml)
+	}
+}
+
+func Test_Parse_Environment_Background(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenefile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	image := scene.NewCanvas(4, 2)
+	image.WritePixel(1, 0, scene.Red)
+	image.WritePixel(2, 0, scene.NewColor(0.5, 0.5, 0.5))
+	require.Nil(t, image.Save(filepath.Join(dir, "sky.ppm")))
+
+	srgb, err := scene.LoadCanvas(filepath.Join(dir, "sky.ppm"), scene.ImageTransfer(scene.SRGBTransfer{}))
+	require.Nil(t, err)
+	linear, err := scene.LoadCanvas(filepath.Join(dir, "sky.ppm"))
+	require.Nil(t, err)
+
+	for _, target := range []struct {
+		yaml string
+		ans  scene.Color
+	}{
+		//transferを省くとsRGBとして読む
+		{"", srgb.Pixels[0][2]},
+		{"  transfer: srgb\n", srgb.Pixels[0][2]},
+		{"  transfer: linear\n", linear.Pixels[0][2]},
+	} {
+		s, err := Parse([]byte(cameraItem+"- add: background\n  type: environment\n  file: sky.ppm\n"+target.yaml), filepath.Join(dir, "scene.yaml"))
+		require.Nil(t, err, target.yaml)
+
+		env, ok := s.Settings.Background.(scene.EnvironmentMap)
+		require.True(t, ok)
+		require.Equal(t, 4, env.Image.Width)
+		require.Equal(t, scene.Red, env.Image.Pixels[0][1])
+		require.Equal(t, target.ans, env.Image.Pixels[0][2], target.yaml)
+	}
+	require.NotEqual(t, srgb.Pixels[0][2], linear.Pixels[0][2])
+}
+
+func Test_Parse_Background_Errors(t *testing.T) {
+	for _, target := range []struct {
+		yaml string
+		ans  string
+	}{
+		{"- add: background\n  type: stars\n", "test.yaml:10:9: unknown background type \"stars\""},
+		{"- add: background\n  type: environment\n  file: missing.ppm\n", "test.yaml:11:9: open missing.ppm: no such file or directory"},
+		{"- add: background\n  type: environment\n  file: sky.ppm\n  transfer: rec709\n", "test.yaml:12:13: transfer must be linear, srgb or a positive gamma, got \"rec709\""},
+		{"- add: background\n  type: solid\n- add: background\n  type: solid\n", "test.yaml:11:3: scene has more than one background"},
+	} {
+		_, err := Parse([]byte(cameraItem+target.yaml), "test.yaml")
+		require.NotNil(t, err, target.yaml)
+		require.Equal(t, target.ans, err.Error(), target.yaml)
+	}
+}
+
+func Test_Parse_Map_Pattern(t *testing.T) {
+	s, err := Parse([]byte(cameraItem+`
+- add: sphere
+  material:
+    pattern:
+      type: map
+      mapping: spherical
+      uv-pattern:
+        type: checkers
+        width: 16
+        height: 8
+        colors:
+          - [0, 0, 0]
+          - [1, 1, 1]
+- add: plane
+  material:
+    pattern:
+      type: map
+      uv-pattern:
+        type: checkers
+        colors: [[1, 0, 0], [0, 0, 1]]
+      transform:
+        - [scale, 2, 2, 2]
+- add: cube
+  material:
+    pattern:
+      type: map
+      mapping: cube
+      left: {type: checkers, colors: [[1, 0, 0], [1, 0, 0]]}
+      right: {type: checkers, colors: [[0, 1, 0], [0, 1, 0]]}
+      front: {type: checkers, colors: [[0, 0, 1], [0, 0, 1]]}
+      back: {type: checkers, colors: [[1, 1, 0], [1, 1, 0]]}
+      up: {type: checkers, colors: [[0, 1, 1], [0, 1, 1]]}
+      down: {type: checkers, colors: [[1, 0, 1], [1, 0, 1]]}
+`), "test.yaml")
+	require.Nil(t, err)
+
+	sphere := s.World.Objects[0].GetMaterial().Pattern.(scene.TextureMapPattern)
+	require.Equal(t, scene.NewUVCheckers(16, 8, scene.Black, scene.White), sphere.UVPattern)
+	require.NotNil(t, sphere.Mapping)
+
+	plane := s.World.Objects[1].GetMaterial().Pattern.(scene.TextureMapPattern)
+	require.Nil(t, plane.Mapping)
+	require.Equal(t, scene.NewUVCheckers(2, 2, scene.Red, scene.Blue), plane.UVPattern)
+	require.Equal(t, calc.NewScale(2, 2, 2), plane.GetTransform())
+
+	cube := s.World.Objects[2].GetMaterial().Pattern.(scene.CubeMapPattern)
+	require.Equal(t, scene.Blue, cube.PatternAt(calc.NewPoint(0, 0, 1)))
+	require.Equal(t, scene.NewColor(1, 0, 1), cube.PatternAt(calc.NewPoint(0, -1, 0)))
+}
+
+func Test_Parse_Map_Pattern_Errors(t *testing.T) {
+	for _, target := range []struct {
+		yaml string
+		ans  string
+	}{
+		{"    pattern: {type: map, mapping: toroidal, uv-pattern: {type: checkers, colors: [[0, 0, 0], [1, 1, 1]]}}\n", "test.yaml:11:35: unknown mapping \"toroidal\""},
+		{"    pattern: {type: map, uv-pattern: {type: stripes, colors: [[0, 0, 0], [1, 1, 1]]}}\n", "test.yaml:11:45: unknown uv pattern type \"stripes\""},
+		{"    pattern: {type: map, mapping: spherical, left: {type: checkers, colors: [[0, 0, 0], [1, 1, 1]]}}\n", "test.yaml:11:14: faces can only be given with mapping: cube"},
+		{"    pattern: {type: map, mapping: cube, left: {type: checkers, colors: [[0, 0, 0], [1, 1, 1]]}}\n", "test.yaml:11:14: missing \"right\""},
+	} {
+		_, err := Parse([]byte(cameraItem+"- add: cube\n  material:\n"+target.yaml), "test.yaml")
+		require.NotNil(t, err, target.yaml)
+		require.Equal(t, target.ans, err.Error(), target.yaml)
+	}
+}
+
+func Test_Parse_Image_Texture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenefile")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	image := scene.NewCanvas(2, 1)
+	image.WritePixel(0, 0, scene.White)
+	require.Nil(t, image.Save(filepath.Join(dir, "wood.png")))
+
+	s, err := Parse([]byte(cameraItem+`
+- define: wood
+  value:
+    type: image
+    file: wood.png
+    filter: nearest
+    address: mirror
+- add: sphere
+  material:
+    pattern: {type: map, uv-pattern: wood}
+- add: cube
+  material:
+    pattern: {type: map, mapping: cube, uv-pattern: wood}
+- add: plane
+  material:
+    pattern:
+      type: map
+      uv-pattern: {type: image, file: wood.png, transfer: linear}
+`), filepath.Join(dir, "scene.yaml"))
+	require.Nil(t, err)
+
+	sphere := s.World.Objects[0].GetMaterial().Pattern.(scene.TextureMapPattern).UVPattern.(scene.ImageTexture)
+	require.Equal(t, scene.TextureNearest, sphere.Filter)
+	require.Equal(t, scene.TextureMirror, sphere.Address)
+	require.Equal(t, scene.White, sphere.Image.Pixels[0][0])
+
+	//同じ画像は一度だけ読み込む
+	cube := s.World.Objects[1].GetMaterial().Pattern.(scene.TextureMapPattern).UVPattern.(scene.ImageTexture)
+	require.True(t, sphere.Image == cube.Image)
+
+	plane := s.World.Objects[2].GetMaterial().Pattern.(scene.TextureMapPattern).UVPattern.(scene.ImageTexture)
+	require.Equal(t, scene.TextureBilinear, plane.Filter)
+	require.Equal(t, scene.TextureWrap, plane.Address)
+	require.False(t, sphere.Image == plane.Image)
+}
+
+func Test_Parse_Transfer(t *testing.T) {
+	for _, target := range []struct {
+		name string
+		ans  scene.Transfer
+	}{
+		{"linear", scene.LinearTransfer{}},
+		{"srgb", scene.SRGBTransfer{}},
+		{"2.2", scene.NewGammaTransfer(2.2)},
+	} {
+		transfer, err := ParseTransfer(target.name)
+		require.Nil(t, err, target.name)
+		require.Equal(t, target.ans, transfer, target.name)
+	}
+
+	for _, name := range []string{"rec709", "0", "-1", ""} {
+		_, err := ParseTransfer(name)
+		require.NotNil(t, err, name)
+	}
+}
+
+func Test_Parse_Image_Texture_Errors(t *testing.T) {
+	for _, target := range []struct {
+		yaml string
+		ans  string
+	}{
+		{"{type: image, file: wood.png, filter: cubic}", "test.yaml:11:76: unknown texture filter \"cubic\""},
+		{"{type: image, file: wood.png, address: repeat}", "test.yaml:11:77: unknown texture address \"repeat\""},
+		{"{type: image, file: wood.png, transfer: rec709}", "test.yaml:11:78: transfer must be linear, srgb or a positive gamma, got \"rec709\""},
+		{"{type: image, file: wood.png}", "test.yaml:11:58: open wood.png: no such file or directory"},
+		{"{type: image}", "test.yaml:11:38: missing \"file\""},
+	} {
+		_, err := Parse([]byte(cameraItem+"- add: cube\n  material:\n    pattern: {type: map, uv-pattern: "+target.yaml+"}\n"), "test.yaml")
+		require.NotNil(t, err, target.yaml)
+		require.Equal(t, target.ans, err.Error(), target.yaml)
+	}
+}
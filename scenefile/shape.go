@@ -0,0 +1,337 @@
+package scenefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"rayGo/scene"
+	"rayGo/util"
+
+	"gopkg.in/yaml.v3"
+)
+
+//defineしたshapeが自分自身をaddしていると終わらないので深さを制限する
+const maxShapeDepth = 64
+
+var shapeKeys = []string{"add", "material", "transform"}
+
+//materialを持たないshapeはgroupなどの親のmaterialを引き継ぐ
+func (l *loader) loadShape(item *yaml.Node) (scene.Shape, error) {
+	return l.shape(item, nil, 0)
+}
+
+func (l *loader) shape(item *yaml.Node, inherited *scene.Material, depth int) (scene.Shape, error) {
+	if depth > maxShapeDepth {
+		return nil, l.errorf(item, "shapes are nested too deeply")
+	}
+
+	fields, err := l.mapping(item)
+	if err != nil {
+		return nil, err
+	}
+
+	addNode, err := fields.require("add")
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := l.string(addNode)
+	if err != nil {
+		return nil, err
+	}
+
+	material := inherited
+	if materialNode, ok := fields.get("material"); ok {
+		if material, err = l.material(materialNode); err != nil {
+			return nil, err
+		}
+	}
+
+	var shape scene.Shape
+	switch kind {
+	case "sphere", "plane", "cube":
+		if err := fields.allow(shapeKeys...); err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "sphere":
+			shape = scene.NewSphere(1)
+		case "plane":
+			shape = scene.NewPlane()
+		default:
+			shape = scene.NewCube()
+		}
+	case "cylinder", "cone":
+		shape, err = l.cylinder(kind, fields)
+	case "triangle":
+		shape, err = l.triangle(fields)
+	case "group":
+		shape, err = l.group(fields, material, depth)
+	case "csg":
+		shape, err = l.csg(fields, material, depth)
+	case "obj":
+		shape, err = l.obj(fields, material)
+	default:
+		return l.definedShape(addNode, fields, inherited, depth)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//groupとcsgは子にmaterialを渡してあるので自分には設定しない
+	if material != nil && kind != "group" && kind != "csg" && kind != "obj" {
+		shape.SetMaterial(material)
+	}
+
+	if transformNode, ok := fields.get("transform"); ok {
+		mat, err := l.transform(transformNode)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := shape.SetTransform(mat); err != nil {
+			return nil, l.errorf(transformNode, "%v", err)
+		}
+	}
+
+	return shape, nil
+}
+
+//add: nameでdefineしたshapeを使う、itemに書いた項目はdefineの項目より優先される
+func (l *loader) definedShape(addNode *yaml.Node, fields *mapping, inherited *scene.Material, depth int) (scene.Shape, error) {
+	base, ok := l.defines[addNode.Value]
+	if !ok {
+		return nil, l.errorf(addNode, "unknown shape %q", addNode.Value)
+	}
+
+	if base.Kind != yaml.MappingNode {
+		return nil, l.errorf(addNode, "%q is not a shape", addNode.Value)
+	}
+
+	//addだけはdefineの方を使う
+	override := &yaml.Node{
+		Kind:   yaml.MappingNode,
+		Line:   fields.node.Line,
+		Column: fields.node.Column,
+	}
+	for i := 0; i+1 < len(fields.node.Content); i += 2 {
+		if fields.node.Content[i].Value != "add" {
+			override.Content = append(override.Content, fields.node.Content[i], fields.node.Content[i+1])
+		}
+	}
+
+	merged, err := l.merge(base, override)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.shape(merged, inherited, depth+1)
+}
+
+func (l *loader) cylinder(kind string, fields *mapping) (scene.Shape, error) {
+	if err := fields.allow(append(shapeKeys, "min", "max", "closed")...); err != nil {
+		return nil, err
+	}
+
+	min, err := fields.float("min", -util.Inf)
+	if err != nil {
+		return nil, err
+	}
+
+	max, err := fields.float("max", util.Inf)
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := fields.bool("closed", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "cone" {
+		return scene.NewCone(scene.ConeMin(min), scene.ConeMax(max), scene.ConeClosed(closed)), nil
+	}
+
+	return scene.NewCyliner(scene.CynMin(min), scene.CynMax(max), scene.CynClosed(closed)), nil
+}
+
+func (l *loader) triangle(fields *mapping) (scene.Shape, error) {
+	if err := fields.allow(append(shapeKeys, "p1", "p2", "p3")...); err != nil {
+		return nil, err
+	}
+
+	var points [3]*yaml.Node
+	for i, key := range []string{"p1", "p2", "p3"} {
+		node, err := fields.require(key)
+		if err != nil {
+			return nil, err
+		}
+
+		points[i] = node
+	}
+
+	p1, err := l.point(points[0])
+	if err != nil {
+		return nil, err
+	}
+
+	p2, err := l.point(points[1])
+	if err != nil {
+		return nil, err
+	}
+
+	p3, err := l.point(points[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewTriangle(p1, p2, p3), nil
+}
+
+func (l *loader) group(fields *mapping, material *scene.Material, depth int) (scene.Shape, error) {
+	if err := fields.allow(append(shapeKeys, "children")...); err != nil {
+		return nil, err
+	}
+
+	group := scene.NewGroup()
+
+	childrenNode, ok := fields.get("children")
+	if !ok {
+		return group, nil
+	}
+
+	if childrenNode.Kind != yaml.SequenceNode {
+		return nil, l.errorf(childrenNode, "children must be a list of shapes")
+	}
+
+	for _, childNode := range childrenNode.Content {
+		child, err := l.shape(childNode, material, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		group.AddChildren(child)
+	}
+
+	return group, nil
+}
+
+func (l *loader) csg(fields *mapping, material *scene.Material, depth int) (scene.Shape, error) {
+	if err := fields.allow(append(shapeKeys, "operation", "left", "right")...); err != nil {
+		return nil, err
+	}
+
+	operationNode, err := fields.require("operation")
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := l.string(operationNode)
+	if err != nil {
+		return nil, err
+	}
+
+	leftNode, err := fields.require("left")
+	if err != nil {
+		return nil, err
+	}
+
+	rightNode, err := fields.require("right")
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := l.shape(leftNode, material, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := l.shape(rightNode, material, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	csg, err := scene.NewCSG(operation, left, right)
+	if err != nil {
+		return nil, l.errorf(operationNode, "unknown csg operation %q", operation)
+	}
+
+	return csg, nil
+}
+
+//fileはscene fileのdirectoryからの相対path、mtllibはobj fileのdirectoryからの相対path
+func (l *loader) obj(fields *mapping, material *scene.Material) (scene.Shape, error) {
+	if err := fields.allow(append(shapeKeys, "file")...); err != nil {
+		return nil, err
+	}
+
+	fileNode, err := fields.require("file")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := l.string(fileNode)
+	if err != nil {
+		return nil, err
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.baseDir, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, l.errorf(fileNode, "%v", err)
+	}
+	defer file.Close()
+
+	parser := scene.NewParser()
+	parser.BaseDir = filepath.Dir(path)
+	if err := parser.Parse(file); err != nil {
+		return nil, l.objError(path, fileNode, err)
+	}
+
+	//scene fileでmaterialを書いたときはmtlのmaterialより優先する
+	group := parser.ToGroup()
+	if material != nil {
+		setMaterial(group, material)
+	}
+
+	return group, nil
+}
+
+//parserのエラーはobj(mtlならそのfile)の行と列で報告する
+func (l *loader) objError(path string, fileNode *yaml.Node, err error) error {
+	parserErr, ok := err.(scene.ParserError)
+	if !ok || parserErr.Line == 0 {
+		return l.errorf(fileNode, "%v", err)
+	}
+
+	if parserErr.File != "" {
+		path = parserErr.File
+	}
+
+	return Error{
+		File:    path,
+		Line:    parserErr.Line,
+		Column:  parserErr.Column,
+		Message: fmt.Sprintf("%s: %q", parserErr.Message, parserErr.Text),
+	}
+}
+
+//objの三角形はparserが作るのでまとめてmaterialを設定する
+func setMaterial(shape scene.Shape, material *scene.Material) {
+	switch s := shape.(type) {
+	case *scene.Group:
+		for _, child := range s.Children {
+			setMaterial(child, material)
+		}
+	case *scene.CSG:
+		setMaterial(s.Left, material)
+		setMaterial(s.Right, material)
+	default:
+		s.SetMaterial(material)
+	}
+}
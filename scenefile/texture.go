@@ -0,0 +1,150 @@
+package scenefile
+
+import (
+	"fmt"
+	"path/filepath"
+	"rayGo/scene"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var textureFilters = map[string]scene.TextureFilter{
+	"nearest":  scene.TextureNearest,
+	"bilinear": scene.TextureBilinear,
+}
+
+var textureAddresses = map[string]scene.TextureAddress{
+	"wrap":   scene.TextureWrap,
+	"clamp":  scene.TextureClamp,
+	"mirror": scene.TextureMirror,
+}
+
+//type: imageはfileの画像を貼る、filterはnearestかbilinear、addressはwrap,clamp,mirror
+//写真などはsRGBで保存されているのでtransferを省くとsRGBとしてlinearに戻す
+func (l *loader) imageTexture(fields *mapping) (scene.UVPattern, error) {
+	if err := fields.allow("type", "file", "filter", "address", "transfer"); err != nil {
+		return nil, err
+	}
+
+	var options []scene.TextureOption
+
+	if filterNode, ok := fields.get("filter"); ok {
+		name, err := l.string(filterNode)
+		if err != nil {
+			return nil, err
+		}
+
+		filter, ok := textureFilters[name]
+		if !ok {
+			return nil, l.errorf(filterNode, "unknown texture filter %q", name)
+		}
+
+		options = append(options, scene.TextureFiltering(filter))
+	}
+
+	if addressNode, ok := fields.get("address"); ok {
+		name, err := l.string(addressNode)
+		if err != nil {
+			return nil, err
+		}
+
+		address, ok := textureAddresses[name]
+		if !ok {
+			return nil, l.errorf(addressNode, "unknown texture address %q", name)
+		}
+
+		options = append(options, scene.TextureAddressing(address))
+	}
+
+	transfer, transferName, err := l.imageTransfer(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	fileNode, err := fields.require("file")
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := l.loadImage(fileNode, transfer, transferName)
+	if err != nil {
+		return nil, err
+	}
+
+	return scene.NewImageTexture(image, options...), nil
+}
+
+//画像のtransfer、省略時はsRGB
+//loadImageのcacheのkeyにするため、書かれた値も返す
+func (l *loader) imageTransfer(fields *mapping) (scene.Transfer, string, error) {
+	transferNode, ok := fields.get("transfer")
+	if !ok {
+		return scene.SRGBTransfer{}, "srgb", nil
+	}
+
+	transfer, err := l.transfer(transferNode)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return transfer, transferNode.Value, nil
+}
+
+//linear、srgb、またはgammaの値
+//scene fileのtransferとcommand lineの--transferで同じ書き方を受け付ける
+func ParseTransfer(name string) (scene.Transfer, error) {
+	switch name {
+	case "linear":
+		return scene.LinearTransfer{}, nil
+	case "srgb":
+		return scene.SRGBTransfer{}, nil
+	}
+
+	gamma, err := strconv.ParseFloat(name, 64)
+	if err != nil || gamma <= 0 {
+		return nil, fmt.Errorf("transfer must be linear, srgb or a positive gamma, got %q", name)
+	}
+
+	return scene.NewGammaTransfer(gamma), nil
+}
+
+func (l *loader) transfer(node *yaml.Node) (scene.Transfer, error) {
+	name, err := l.string(node)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := ParseTransfer(name)
+	if err != nil {
+		return nil, l.errorf(node, "%v", err)
+	}
+
+	return transfer, nil
+}
+
+//同じ画像を何度もaddしても一度しか読まない
+func (l *loader) loadImage(fileNode *yaml.Node, transfer scene.Transfer, transferName string) (*scene.Canvas, error) {
+	name, err := l.string(fileNode)
+	if err != nil {
+		return nil, err
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.baseDir, path)
+	}
+
+	key := path + "\x00" + transferName
+	if image, ok := l.images[key]; ok {
+		return image, nil
+	}
+
+	image, err := scene.LoadCanvas(path, scene.ImageTransfer(transfer))
+	if err != nil {
+		return nil, l.errorf(fileNode, "%v", err)
+	}
+
+	l.images[key] = image
+	return image, nil
+}
@@ -0,0 +1,105 @@
+package scenefile
+
+import (
+	"rayGo/calc"
+
+	"gopkg.in/yaml.v3"
+)
+
+//transformのlistを一つの行列にする
+//listの要素は[translate, x, y, z]のような操作かdefineしたtransformの名前
+func (l *loader) transform(node *yaml.Node) (calc.Mat4x4, error) {
+	return l.transformList(node, map[string]bool{})
+}
+
+//resolvingは展開している途中のdefineの名前、同じ名前がもう一度出てきたら循環している
+func (l *loader) transformList(node *yaml.Node, resolving map[string]bool) (calc.Mat4x4, error) {
+	if node.Kind == yaml.ScalarNode {
+		if resolving[node.Value] {
+			return calc.Mat4x4{}, l.errorf(node, "transform %q refers to itself", node.Value)
+		}
+
+		resolving[node.Value] = true
+		defer delete(resolving, node.Value)
+	}
+
+	node, err := l.resolve(node)
+	if err != nil {
+		return calc.Mat4x4{}, err
+	}
+
+	if node.Kind != yaml.SequenceNode {
+		return calc.Mat4x4{}, l.errorf(node, "transform must be a list")
+	}
+
+	ret := calc.Mat4x4(calc.Ident4x4)
+	for _, op := range node.Content {
+		var mat calc.Mat4x4
+		if op.Kind == yaml.ScalarNode {
+			//defineしたtransform
+			mat, err = l.transformList(op, resolving)
+		} else {
+			mat, err = l.transformOp(op)
+		}
+		if err != nil {
+			return calc.Mat4x4{}, err
+		}
+
+		//後に書いたものほど後に適用される
+		ret = mat.MulByMat4x4(ret)
+	}
+
+	return ret, nil
+}
+
+func (l *loader) transformOp(op *yaml.Node) (calc.Mat4x4, error) {
+	if op.Kind != yaml.SequenceNode || len(op.Content) == 0 {
+		return calc.Mat4x4{}, l.errorf(op, "transform must be a list like [translate, x, y, z]")
+	}
+
+	name, err := l.string(op.Content[0])
+	if err != nil {
+		return calc.Mat4x4{}, err
+	}
+
+	args := op.Content[1:]
+	argCount := map[string]int{
+		"translate": 3,
+		"scale":     3,
+		"rotate-x":  1,
+		"rotate-y":  1,
+		"rotate-z":  1,
+		"shear":     6,
+	}
+
+	n, ok := argCount[name]
+	if !ok {
+		return calc.Mat4x4{}, l.errorf(op.Content[0], "unknown transform %q", name)
+	}
+
+	if len(args) != n {
+		return calc.Mat4x4{}, l.errorf(op, "%s takes %d numbers, got %d", name, n, len(args))
+	}
+
+	v := make([]float64, n)
+	for i, arg := range args {
+		if v[i], err = l.float(arg); err != nil {
+			return calc.Mat4x4{}, err
+		}
+	}
+
+	switch name {
+	case "translate":
+		return calc.NewTranslation(v[0], v[1], v[2]), nil
+	case "scale":
+		return calc.NewScale(v[0], v[1], v[2]), nil
+	case "rotate-x":
+		return calc.NewRotateX(v[0]), nil
+	case "rotate-y":
+		return calc.NewRotateY(v[0]), nil
+	case "rotate-z":
+		return calc.NewRotateZ(v[0]), nil
+	default:
+		return calc.NewShearing(v[0], v[1], v[2], v[3], v[4], v[5]), nil
+	}
+}
@@ -3,7 +3,6 @@ package box
 import (
 	"fmt"
 	"math"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -88,13 +87,13 @@ func TestBoxRoom(t *testing.T) {
 
 	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), glassfloor, middle, left, right, wall1, wall2)
 
-	camera := scene.NewCamera(960, 1200, math.Pi/3)
+	camera := scene.NewCamera(1200, 960, math.Pi/3)
 
-	camera.Transform = scene.ViewTransform(
+	camera.SetTransform(scene.ViewTransform(
 		calc.NewPoint(0, 1.5, -5),
 		calc.NewPoint(-1.25, -0.7, 0),
 		calc.NewVector(0, 1, 0),
-	)
+	))
 
 	canvas, err := world.Render(camera)
 	if err != nil {
@@ -102,13 +101,7 @@ func TestBoxRoom(t *testing.T) {
 		return
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
@@ -3,7 +3,6 @@ package chap10
 import (
 	"fmt"
 	"math"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -64,12 +63,12 @@ func TestChap10(t *testing.T) {
 
 	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), floor, middle, left, right)
 
-	camera := scene.NewCamera(600, 900, math.Pi/3)
-	camera.Transform = scene.ViewTransform(
+	camera := scene.NewCamera(900, 600, math.Pi/3)
+	camera.SetTransform(scene.ViewTransform(
 		calc.NewPoint(0, 1.5, -5),
 		calc.NewPoint(-1.25, -0.7, 0),
 		calc.NewVector(0, 1, 0),
-	)
+	))
 
 	canvas, err := world.Render(camera)
 	if err != nil {
@@ -77,13 +76,7 @@ func TestChap10(t *testing.T) {
 		return
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
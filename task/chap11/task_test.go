@@ -3,7 +3,6 @@ package chap11
 import (
 	"fmt"
 	"math"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -66,12 +65,12 @@ func TestChap11(t *testing.T) {
 
 	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), glassfloor, middle, left, right)
 
-	camera := scene.NewCamera(300, 450, math.Pi/3)
-	camera.Transform = scene.ViewTransform(
+	camera := scene.NewCamera(450, 300, math.Pi/3)
+	camera.SetTransform(scene.ViewTransform(
 		calc.NewPoint(0, 1.5, -5),
 		calc.NewPoint(-1.25, -0.7, 0),
 		calc.NewVector(0, 1, 0),
-	)
+	))
 
 	canvas, err := world.Render(camera)
 	if err != nil {
@@ -79,13 +78,7 @@ func TestChap11(t *testing.T) {
 		return
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
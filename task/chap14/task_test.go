@@ -3,7 +3,6 @@ package chap14
 import (
 	"fmt"
 	"math"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -61,12 +60,12 @@ func TestChap14(t *testing.T) {
 
 	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), glassfloor, hex)
 
-	camera := scene.NewCamera(300, 450, math.Pi/3)
-	camera.Transform = scene.ViewTransform(
+	camera := scene.NewCamera(450, 300, math.Pi/3)
+	camera.SetTransform(scene.ViewTransform(
 		calc.NewPoint(0, 1.5, -5),
 		calc.NewPoint(-1.25, -0.7, 0),
 		calc.NewVector(0, 1, 0),
-	)
+	))
 
 	canvas, err := world.Render(camera)
 	if err != nil {
@@ -74,13 +73,7 @@ func TestChap14(t *testing.T) {
 		return
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
@@ -3,7 +3,6 @@ package chap15
 import (
 	"fmt"
 	"math"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -36,12 +35,12 @@ func TestChap15(t *testing.T) {
 
 	world := scene.NewWorld(scene.NewLight(calc.NewPoint(-10, 10, -10), scene.NewColor(1, 1, 1)), glassfloor, teapot)
 
-	camera := scene.NewCamera(300, 450, math.Pi/3)
-	camera.Transform = scene.ViewTransform(
+	camera := scene.NewCamera(450, 300, math.Pi/3)
+	camera.SetTransform(scene.ViewTransform(
 		calc.NewPoint(0, 1.5, -5),
 		calc.NewPoint(-1.25, -0.7, 0),
 		calc.NewVector(0, 1, 0),
-	)
+	))
 
 	canvas, err := world.Render(camera)
 	if err != nil {
@@ -49,13 +48,7 @@ func TestChap15(t *testing.T) {
 		return
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
@@ -2,7 +2,6 @@ package task
 
 import (
 	"fmt"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -48,13 +47,7 @@ func TestChap5(t *testing.T) {
 		}
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
@@ -2,7 +2,6 @@ package task
 
 import (
 	"fmt"
-	"os"
 	"rayGo/calc"
 	"rayGo/scene"
 	"testing"
@@ -59,20 +58,14 @@ func TestChap6(t *testing.T) {
 			}
 			eye_vec := calc.NegTuple(ray.Direction)
 
-			color, _ := light.Lighting(hit.Object.GetMaterial(), point, eye_vec, normal_vec, false, sphere)
+			color, _ := light.Lighting(hit.Object.GetMaterial(), point, eye_vec, normal_vec, 1.0, sphere)
 
 			canvas.WritePixel(x, y, color)
 
 		}
 	}
 
-	fp, err := os.Create("test.ppm")
-	if err != nil {
+	if err := canvas.Save("test.ppm"); err != nil {
 		fmt.Println(err)
-		return
 	}
-
-	defer fp.Close()
-
-	fp.WriteString(canvas.ToPPM())
 }
@@ -2,40 +2,43 @@ package util
 
 import (
 	"math"
-	"sync"
+	"sync/atomic"
 
 	"github.com/google/go-cmp/cmp"
 )
 
+//並列Render中に複数のgoroutineから読まれるのでfloat64のbitをatomicに読み書きする
 type Epsilon struct {
-	value float64
-	lock  sync.Mutex
+	bits uint64
+}
+
+func (e *Epsilon) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&e.bits))
 }
 
 var DefaultEpsilon = 0.00001
 var EPSILON = Epsilon{
-	value: DefaultEpsilon,
+	bits: math.Float64bits(DefaultEpsilon),
 }
 
 func FloatEqual(a, b float64) bool {
-	if math.Abs(a-b) < EPSILON.value {
+	if math.Abs(a-b) < EPSILON.Value() {
 		return true
 	}
 
 	return false
 }
 
+//FloatEqualなどの比較に使う全体の値を変える
+//renderの交差判定はRenderSettings.Epsilonをrayに付けて使うので、renderごとの設定には使わない
 func SetEpsilon(num float64) {
-	EPSILON.lock.Lock()
-	defer EPSILON.lock.Unlock()
-	EPSILON.value = num
-
+	atomic.StoreUint64(&EPSILON.bits, math.Float64bits(num))
 }
 
 var FloatComparer = cmp.Comparer(FloatEqual)
 
 func IsNearlyEqualZero(num float64) bool {
-	if math.Abs(num) < EPSILON.value {
+	if math.Abs(num) < EPSILON.Value() {
 		return true
 	}
 